@@ -8,15 +8,19 @@ import (
 
 // NewAddCmd creates a new cobra command for adding todos.
 func NewAddCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "add [TASK]",
 		Short: "Add a new todo item to the list",
 		Long: `Adds a new task to the list and prints the newly added task.
 If [TASK] contains multiple lines, each line is added as a separate task.
+With --date, or date_on_add set in your config, today's date is added as the creation date.
 
 # add "Buy milk" to the list
 togodo add "Buy milk"
 
+# add "Buy milk" to the list with today's date as the creation date
+togodo add --date "Buy milk"
+
 # add multiple tasks to the list
 togodo add "Buy milk
 Buy eggs
@@ -26,8 +30,14 @@ Buy bread"
 		Args:    cobra.MinimumNArgs(1),
 		Aliases: []string{"a"},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			dated, _ := cmd.Flags().GetBool("date")
+
 			// Business logic - delegated to service
-			todos, err := service.AddTodos(args)
+			addTodos := service.AddTodos
+			if dated {
+				addTodos = service.AddDatedTodos
+			}
+			todos, err := addTodos(args)
 			if err != nil {
 				return err
 			}
@@ -39,4 +49,8 @@ Buy bread"
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolP("date", "t", false, "Prepend today's date as the creation date")
+
+	return cmd
 }
@@ -181,3 +181,24 @@ func TestAddCmd_DuplicateTasks(t *testing.T) {
 		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
 	}
 }
+
+func TestAddCmd_WithDate(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithClock(testNow))
+
+	// Test adding a task with a creation date
+	todos, err := service.AddDatedTodos([]string{"(A) new task +project @context"})
+	assertNoError(t, err)
+
+	if len(todos) != 1 {
+		t.Fatalf("Expected 1 todo, got %d", len(todos))
+	}
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) 2024-01-15 new task +project @context\n"
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
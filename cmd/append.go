@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/todotxtlib"
+	"github.com/spf13/cobra"
+)
+
+// NewAppendCmd creates a new cobra command for adding text to the end of a todo.
+func NewAppendCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
+	return &cobra.Command{
+		Use:   "append LINE_NUMBER TEXT",
+		Short: "Add text to the end of a todo item",
+		Long: `Adds text to the end of the task on a line, and prints the updated task.
+
+# add a context and a project to the task on line 1
+togodo append 1 @phone +family
+`,
+
+		Args:        cobra.MinimumNArgs(2),
+		Aliases:     []string{"app"},
+		Annotations: map[string]string{lineArgsAnnotation: lineArgsFirst},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			index, err := lineIndex(service, args[0])
+			if err != nil {
+				return err
+			}
+
+			// Business logic - delegated to service
+			todo, err := service.AppendToTodo(index, strings.Join(args[1:], " "))
+			if err != nil {
+				return err
+			}
+
+			// Presentation logic - handled by presenter
+			presenter.Print(todo)
+			return nil
+		},
+	}
+}
+
+// NewPrependCmd creates a new cobra command for adding text to the start of a todo.
+func NewPrependCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
+	return &cobra.Command{
+		Use:   "prepend LINE_NUMBER TEXT",
+		Short: "Add text to the start of a todo item",
+		Long: `Adds text to the start of the task on a line, after its done marker, priority and dates, and prints the
+updated task.
+
+# turn "(A) call mum" on line 1 into "(A) urgently call mum"
+togodo prepend 1 urgently
+`,
+
+		Args:        cobra.MinimumNArgs(2),
+		Aliases:     []string{"prep"},
+		Annotations: map[string]string{lineArgsAnnotation: lineArgsFirst},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			index, err := lineIndex(service, args[0])
+			if err != nil {
+				return err
+			}
+
+			// Business logic - delegated to service
+			todo, err := service.PrependToTodo(index, strings.Join(args[1:], " "))
+			if err != nil {
+				return err
+			}
+
+			// Presentation logic - handled by presenter
+			presenter.Print(todo)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/gkarolyi/togodo/todotxtlib"
+)
+
+func TestAppendCmd(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	todo, err := service.AppendToTodo(0, "@context3 due:2024-02-01")
+	assertNoError(t, err)
+
+	if len(todo.Contexts) != 2 {
+		t.Errorf("Expected 2 contexts, got %v", todo.Contexts)
+	}
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1 @context3 due:2024-02-01\n" +
+		"(B) test todo 2 +project1 @context2\n" +
+		"x (C) test todo 3 +project1 @context1\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestAppendCmd_InvalidLineNumber(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	_, err := service.AppendToTodo(9, "more")
+	assertError(t, err)
+	assertContains(t, err.Error(), "failed to append to todo")
+}
+
+func TestPrependCmd(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	// Text goes after the done marker and priority
+	_, err := service.PrependToTodo(1, "urgent")
+	assertNoError(t, err)
+	_, err = service.PrependToTodo(2, "old")
+	assertNoError(t, err)
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1\n" +
+		"(B) urgent test todo 2 +project1 @context2\n" +
+		"x (C) old test todo 3 +project1 @context1\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestPrependCmd_InvalidLineNumber(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	_, err := service.PrependToTodo(9, "more")
+	assertError(t, err)
+	assertContains(t, err.Error(), "failed to prepend to todo")
+}
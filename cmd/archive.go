@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/todotxtlib"
+	"github.com/spf13/cobra"
+)
+
+// NewArchiveCmd creates a new cobra command for moving done todos to done.txt.
+func NewArchiveCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
+	return &cobra.Command{
+		Use:   "archive",
+		Short: "Move done tasks from your todo.txt to done.txt",
+		Long: `Moves done tasks from your todo.txt to the end of your done.txt, and prints the tasks that were archived.
+done.txt is kept next to your todo.txt unless done_txt_path is set in your config.
+
+# archive done tasks
+togodo archive`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Business logic - delegated to service
+			todos, err := service.ArchiveDoneTodos()
+			if err != nil {
+				return err
+			}
+
+			// Presentation logic - handled by presenter
+			return presenter.PrintList(todos)
+		},
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gkarolyi/togodo/todotxtlib"
+)
+
+func TestArchiveCmd_WithDoneTasks(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	var archive bytes.Buffer
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithArchive(todotxtlib.NewBufferWriter(&archive)))
+
+	// Mark one more task as done to have multiple done tasks
+	repo.ToggleDone(0)
+
+	// Execute archive
+	todos, err := service.ArchiveDoneTodos()
+	assertNoError(t, err)
+
+	// Verify two todos were archived
+	if len(todos) != 2 {
+		t.Fatalf("Expected 2 archived todos, got %d", len(todos))
+	}
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(B) test todo 2 +project1 @context2\n"
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+
+	assertContains(t, archive.String(), "test todo 1 +project2 @context1\n")
+	assertContains(t, archive.String(), "x (C) test todo 3 +project1 @context1\n")
+}
+
+func TestArchiveCmd_NoDoneTasks(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	var archive bytes.Buffer
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithArchive(todotxtlib.NewBufferWriter(&archive)))
+
+	// Add only undone tasks
+	repo.Add("task 1")
+	repo.Add("task 2")
+
+	// Execute archive
+	todos, err := service.ArchiveDoneTodos()
+	assertNoError(t, err)
+
+	// Verify nothing was archived
+	if len(todos) != 0 {
+		t.Fatalf("Expected 0 archived todos, got %d", len(todos))
+	}
+	if archive.Len() != 0 {
+		t.Errorf("Expected empty archive, got:\n%s", archive.String())
+	}
+}
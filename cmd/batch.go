@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/internal/config"
+	"github.com/gkarolyi/togodo/todotxtlib"
+	"github.com/spf13/cobra"
+)
+
+// addBatchFlags adds the flags of commands that change several todos at once
+func addBatchFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("where", "w", "", "Change every task matching this query instead of tasks on given lines")
+	cmd.Flags().BoolP("dry-run", "n", false, "Print the tasks that would be changed without changing them")
+	cmd.Flags().BoolP("yes", "y", false, "Change tasks matching --where without asking for confirmation")
+}
+
+// selectIndices returns the indices (0-based) of the todos matching both the --where
+// query and the given filter, or else of the line numbers in lineArgs
+func selectIndices(cmd *cobra.Command, service todotxtlib.TodoService, lineArgs []string, filter todotxtlib.Filter) ([]int, error) {
+	where, _ := cmd.Flags().GetString("where")
+	if where == "" {
+		if len(lineArgs) == 0 {
+			return nil, fmt.Errorf("expected line numbers or --where")
+		}
+		return lineIndices(service, lineArgs)
+	}
+
+	if len(lineArgs) > 0 {
+		return nil, fmt.Errorf("expected either line numbers or --where, not both")
+	}
+	return queryIndices(service, where, filter)
+}
+
+// runBatch applies an operation to the todos at the given indices (0-based) and
+// prints the changed todos. With --dry-run the todos are only printed. Changing
+// more todos matching --where than the confirm_threshold config asks for
+// confirmation first, unless --yes is passed. The lock on todo.txt is released
+// while asking, and nothing is changed if todo.txt was changed meanwhile
+func runBatch(
+	cmd *cobra.Command,
+	service todotxtlib.TodoService,
+	presenter *cli.Presenter,
+	verb string,
+	indices []int,
+	apply func(indices []int) ([]todotxtlib.Todo, error),
+) error {
+	where, _ := cmd.Flags().GetString("where")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	if where != "" && len(indices) == 0 {
+		return presenter.WriteLine("No matching todos")
+	}
+
+	if dryRun {
+		todos, err := todosAt(service, indices)
+		if err != nil {
+			return err
+		}
+		presenter.WriteLine(fmt.Sprintf("Would %s %s:", verb, countTodos(len(todos))))
+		for _, todo := range todos {
+			presenter.Print(todo)
+		}
+		return nil
+	}
+
+	if threshold := config.GetConfirmThreshold(); where != "" && !yes && threshold > 0 && len(indices) > threshold {
+		question := fmt.Sprintf("%s %s matching %q?", verb, countTodos(len(indices)), where)
+		confirmed := false
+		changed, err := withoutLock(cmd, service, func() {
+			confirmed = confirm(cmd.InOrStdin(), cmd.ErrOrStderr(), question)
+		})
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return fmt.Errorf("cancelled, nothing was changed; pass --yes to skip confirmation")
+		}
+		if changed {
+			return fmt.Errorf("todo.txt was changed while waiting for confirmation, nothing was changed; run the command again")
+		}
+	}
+
+	// Business logic - delegated to service
+	todos, err := apply(indices)
+	if err != nil {
+		return err
+	}
+
+	// Presentation logic - handled by presenter
+	for _, todo := range todos {
+		presenter.Print(todo)
+	}
+	return nil
+}
+
+// todosAt returns the todos at the given indices (0-based)
+func todosAt(service todotxtlib.TodoService, indices []int) ([]todotxtlib.Todo, error) {
+	allTodos, err := service.FilterTodos(todotxtlib.Filter{})
+	if err != nil {
+		return nil, err
+	}
+
+	todos := make([]todotxtlib.Todo, len(indices))
+	for i, index := range indices {
+		if index < 0 || index >= len(allTodos) {
+			return nil, fmt.Errorf("no todo on line %d", index+1)
+		}
+		todos[i] = allTodos[index]
+	}
+	return todos, nil
+}
+
+// confirm asks a yes or no question, and reports whether it was answered with yes
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s%s [y/N] ", strings.ToUpper(question[:1]), question[1:])
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
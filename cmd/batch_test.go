@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/todotxtlib"
+	"github.com/spf13/viper"
+)
+
+const unchangedTestOutput = "(A) test todo 1 +project2 @context1\n" +
+	"(B) test todo 2 +project1 @context2\n" +
+	"x (C) test todo 3 +project1 @context1\n"
+
+func TestSelectIndices(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	tests := []struct {
+		name    string
+		where   string
+		args    []string
+		want    []int
+		wantErr bool
+	}{
+		{name: "line numbers", args: []string{"3", "1"}, want: []int{2, 0}},
+		{name: "where", where: "+project1", want: []int{1, 2}},
+		{name: "where without matches", where: "+nothing", want: []int{}},
+		{name: "neither", wantErr: true},
+		{name: "both", where: "+project1", args: []string{"1"}, wantErr: true},
+		{name: "invalid query", where: "(", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewDoCmd(service, cli.NewPresenter())
+			cmd.Flags().Set("where", tt.where)
+
+			got, err := selectIndices(cmd, service, tt.args, todotxtlib.Filter{})
+			if tt.wantErr {
+				assertError(t, err)
+				return
+			}
+			assertNoError(t, err)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("selectIndices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoCmd_Where(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithClock(testNow))
+
+	cmd := NewDoCmd(service, cli.NewPresenter())
+	cmd.Flags().Set("where", "@context1 -x")
+	assertNoError(t, cmd.RunE(cmd, nil))
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(B) test todo 2 +project1 @context2\n" +
+		"x (C) test todo 3 +project1 @context1\n" +
+		"x 2024-01-15 test todo 1 +project2 @context1\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestDoCmd_WhereSkipsDone(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithClock(testNow))
+
+	// The done todo 3 matches too, but is not reopened
+	cmd := NewDoCmd(service, cli.NewPresenter())
+	cmd.Flags().Set("where", "+project1")
+	assertNoError(t, cmd.RunE(cmd, nil))
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1\n" +
+		"x (C) test todo 3 +project1 @context1\n" +
+		"x 2024-01-15 test todo 2 +project1 @context2\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+
+	// Only done todos match, so nothing changes
+	cmd = NewDoCmd(service, cli.NewPresenter())
+	cmd.Flags().Set("where", "x")
+	assertNoError(t, cmd.RunE(cmd, nil))
+
+	unchanged, err := repo.WriteToString()
+	assertNoError(t, err)
+	if unchanged != expectedOutput {
+		t.Errorf("Expected done todos to stay done, got:\n%s", unchanged)
+	}
+}
+
+func TestPriCmd_Where(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	cmd := NewPriCmd(service, cli.NewPresenter())
+	cmd.Flags().Set("where", "+project1 -x")
+	assertNoError(t, cmd.RunE(cmd, []string{"D"}))
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1\n" +
+		"(D) test todo 2 +project1 @context2\n" +
+		"x (C) test todo 3 +project1 @context1\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestRmCmd_DryRun(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	cmd := NewRmCmd(service, cli.NewPresenter())
+	cmd.Flags().Set("where", "+project1")
+	cmd.Flags().Set("dry-run", "true")
+	assertNoError(t, cmd.RunE(cmd, nil))
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+	if output != unchangedTestOutput {
+		t.Errorf("Expected --dry-run to change nothing, got:\n%s", output)
+	}
+
+	// Line numbers that do not exist are reported
+	cmd = NewRmCmd(service, cli.NewPresenter())
+	cmd.Flags().Set("dry-run", "true")
+	assertError(t, cmd.RunE(cmd, []string{"1", "9"}))
+}
+
+func TestRmCmd_Confirm(t *testing.T) {
+	viper.Set("confirm_threshold", 1)
+	t.Cleanup(viper.Reset)
+
+	tests := []struct {
+		name    string
+		input   string
+		yes     bool
+		removed bool
+	}{
+		{name: "declined", input: "n\n", removed: false},
+		{name: "no answer", input: "", removed: false},
+		{name: "confirmed", input: "y\n", removed: true},
+		{name: "yes flag", yes: true, removed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, _ := setupTestRepository(t)
+			service := todotxtlib.NewTodoService(repo)
+
+			cmd := NewRmCmd(service, cli.NewPresenter())
+			cmd.SetIn(strings.NewReader(tt.input))
+			cmd.SetErr(io.Discard)
+			cmd.Flags().Set("where", "+project1")
+			if tt.yes {
+				cmd.Flags().Set("yes", "true")
+			}
+
+			err := cmd.RunE(cmd, nil)
+			if tt.removed {
+				assertNoError(t, err)
+			} else {
+				assertError(t, err)
+				assertContains(t, err.Error(), "nothing was changed")
+			}
+
+			output, err := repo.WriteToString()
+			assertNoError(t, err)
+			if removed := output != unchangedTestOutput; removed != tt.removed {
+				t.Errorf("Expected removed = %v, got output:\n%s", tt.removed, output)
+			}
+		})
+	}
+}
+
+func TestRmCmd_ConfirmWithoutLock(t *testing.T) {
+	viper.Set("confirm_threshold", 1)
+	t.Cleanup(viper.Reset)
+
+	tests := []struct {
+		name     string
+		change   bool
+		expected string
+		wantErr  string
+	}{
+		{name: "unchanged", expected: "(A) test todo 1 +project2 @context1\n"},
+		{name: "changed meanwhile", change: true, expected: "(A) test todo 1 +project2 @context1\n" +
+			"(B) test todo 2 +project1 @context2\n" +
+			"test todo 4\n" +
+			"x (C) test todo 3 +project1 @context1\n", wantErr: "was changed while waiting"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "todo.txt")
+			if err := os.WriteFile(path, []byte(unchangedTestOutput), 0644); err != nil {
+				t.Fatalf("failed to write todo.txt: %v", err)
+			}
+			// Each service stands in for a separate togodo process
+			newService := func() todotxtlib.TodoService {
+				repo, err := todotxtlib.NewFileRepository(todotxtlib.NewFileReader(path), todotxtlib.NewFileWriter(path),
+					todotxtlib.WithLocking(path, 100*time.Millisecond))
+				assertNoError(t, err)
+				return todotxtlib.NewTodoService(repo)
+			}
+
+			// The other process must not have to wait for the question to be answered
+			var otherErr error
+			answer := &answerReader{answer: strings.NewReader("y\n"), before: func() {
+				other := newService()
+				if tt.change {
+					_, otherErr = other.AddTodos([]string{"test todo 4"})
+					return
+				}
+				var unlock func()
+				if unlock, otherErr = other.Lock(); otherErr == nil {
+					unlock()
+				}
+			}}
+
+			service := newService()
+			rootCmd := NewRootCmd(service, service, cli.NewPresenter(), path)
+			rootCmd.SetArgs([]string{"rm", "--where", "+project1"})
+			rootCmd.SetIn(answer)
+			rootCmd.SetErr(io.Discard)
+
+			err := rootCmd.Execute()
+			assertNoError(t, otherErr)
+			if tt.wantErr != "" {
+				assertError(t, err)
+				assertContains(t, err.Error(), tt.wantErr)
+			} else {
+				assertNoError(t, err)
+			}
+
+			content, err := os.ReadFile(path)
+			assertNoError(t, err)
+			if string(content) != tt.expected {
+				t.Errorf("Expected todo.txt:\n%s\nGot:\n%s", tt.expected, content)
+			}
+		})
+	}
+}
+
+// answerReader answers a question, calling before first as if the user took a while
+type answerReader struct {
+	answer io.Reader
+	before func()
+}
+
+func (r *answerReader) Read(p []byte) (int, error) {
+	if r.before != nil {
+		r.before()
+		r.before = nil
+	}
+	return r.answer.Read(p)
+}
+
+func TestRmCmd_ConfirmBelowThreshold(t *testing.T) {
+	viper.Set("confirm_threshold", 2)
+	t.Cleanup(viper.Reset)
+
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	// Two matching todos do not exceed the threshold, so no answer is needed
+	cmd := NewRmCmd(service, cli.NewPresenter())
+	cmd.SetIn(strings.NewReader(""))
+	cmd.Flags().Set("where", "+project1")
+	assertNoError(t, cmd.RunE(cmd, nil))
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+	if output != "(A) test todo 1 +project2 @context1\n" {
+		t.Errorf("Unexpected output:\n%s", output)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: "y\n", want: true},
+		{input: "YES\n", want: true},
+		{input: " y ", want: true},
+		{input: "n\n", want: false},
+		{input: "\n", want: false},
+		{input: "", want: false},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if got := confirm(strings.NewReader(tt.input), &out, "delete 3 todos?"); got != tt.want {
+			t.Errorf("confirm(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+		if out.String() != "Delete 3 todos? [y/N] " {
+			t.Errorf("confirm() asked %q", out.String())
+		}
+	}
+}
@@ -73,7 +73,15 @@ func showConfig(presenter *cli.Presenter, key string) error {
 func setConfig(presenter *cli.Presenter, key, value string) error {
 	// Validate the key (only allow known configuration keys)
 	validKeys := map[string]bool{
-		"todo_txt_path": true,
+		"todo_txt_path":     true,
+		"keep_priority":     true,
+		"date_on_add":       true,
+		"done_txt_path":     true,
+		"sort":              true,
+		"autosave":          true,
+		"lock_timeout":      true,
+		"smart_case":        true,
+		"confirm_threshold": true,
 	}
 
 	if !validKeys[key] {
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/todotxtlib"
+	"github.com/spf13/cobra"
+)
+
+// NewDepriCmd creates a new cobra command for removing priorities.
+func NewDepriCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
+	return &cobra.Command{
+		Use:   "depri [LINE NUMBER]...",
+		Short: "Remove the priority of a todo item",
+		Long: `Removes the priority of todo items, and prints the updated tasks.
+
+# remove the priority of the todo on line 1
+togodo depri 1
+
+# remove the priority of the todos on lines 1, 2, and 3
+togodo depri 1 2 3
+`,
+
+		Args:        cobra.MinimumNArgs(1),
+		Aliases:     []string{"dp"},
+		Annotations: map[string]string{lineArgsAnnotation: lineArgsAll},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Parse line numbers (convert from 1-based to 0-based), checking that every line exists
+			indices, err := lineIndices(service, args)
+			if err != nil {
+				return err
+			}
+
+			// Business logic - delegated to service
+			todos, err := service.DeprioritizeTodos(indices)
+			if err != nil {
+				return err
+			}
+
+			// Presentation logic - handled by presenter
+			for _, todo := range todos {
+				presenter.Print(todo)
+			}
+			return nil
+		},
+	}
+}
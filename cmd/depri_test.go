@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/gkarolyi/togodo/todotxtlib"
+)
+
+func TestDepriCmd_MultipleTasks(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	indices, err := parseLineNumbers([]string{"1", "2"})
+	assertNoError(t, err)
+
+	todos, err := service.DeprioritizeTodos(indices)
+	assertNoError(t, err)
+
+	if len(todos) != 2 {
+		t.Fatalf("Expected 2 todos, got %d", len(todos))
+	}
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "test todo 1 +project2 @context1\n" +
+		"test todo 2 +project1 @context2\n" +
+		"x (C) test todo 3 +project1 @context1\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestDepriCmd_DoneTask(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	_, err := service.DeprioritizeTodos([]int{2})
+	assertNoError(t, err)
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1\n" +
+		"(B) test todo 2 +project1 @context2\n" +
+		"x test todo 3 +project1 @context1\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestDepriCmd_InvalidLineNumber(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	indices, err := parseLineNumbers([]string{"1", "10"})
+	assertNoError(t, err)
+
+	_, err = service.DeprioritizeTodos(indices)
+	assertError(t, err)
+	assertContains(t, err.Error(), "no todo at index 9")
+
+	// Nothing is changed if one of the line numbers does not exist
+	todos, err := repo.ListAll()
+	assertNoError(t, err)
+	if todos[0].Priority != "A" {
+		t.Errorf("Expected priority A, got %q", todos[0].Priority)
+	}
+}
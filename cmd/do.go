@@ -1,65 +1,61 @@
 package cmd
 
 import (
-	"fmt"
-	"strconv"
-
 	"github.com/gkarolyi/togodo/internal/cli"
 	"github.com/gkarolyi/togodo/todotxtlib"
 	"github.com/spf13/cobra"
 )
 
-// parseLineNumbers converts CLI line numbers (1-based) to repository indices (0-based)
-func parseLineNumbers(args []string) ([]int, error) {
-	indices := make([]int, len(args))
-	for i, arg := range args {
-		lineNumber, err := strconv.Atoi(arg)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert arg to int: %w", err)
-		}
-		if lineNumber < 1 {
-			return nil, fmt.Errorf("line number must be positive, got %d", lineNumber)
-		}
-		indices[i] = lineNumber - 1 // Convert to 0-based
-	}
-	return indices, nil
-}
-
 // NewDoCmd creates a new cobra command for toggling todos.
 func NewDoCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "do [LINE NUMBER]...",
 		Short: "Toggle the done status of a todo item",
 		Long: `Marks a task as done or not done depending on its current status, and prints the toggled task.
-If [LINE_NUMBER] contains multiple line numbers, each todo will be toggled.
+If [LINE_NUMBER] contains multiple line numbers, each todo will be toggled. Instead of line numbers, --where completes
+every pending task matching a query as used by list, leaving tasks that are already done as they are. --dry-run prints
+the tasks that would be changed, and completing more tasks matching --where than the confirm_threshold config (10 by
+default) asks for confirmation unless --yes is passed.
 
 # toggle the done status of the task on line 1
 togodo do 1
 
 # toggle the done status of the tasks on lines 1, 2, and 3
 togodo do 1 2 3
+
+# toggle the tasks on lines 3 to 7, and the last task
+togodo do 3-7 last
+
+# toggle the second to last task
+togodo do -2
+
+# toggle the tasks selected in the TUI with e
+togodo do @selected
+
+# see which pending tasks in +release and @review would be completed, then complete them
+togodo do --dry-run --where '+release @review'
+togodo do --where '+release @review'
 `,
 
-		Args:    cobra.MinimumNArgs(1),
-		Aliases: []string{"x"},
+		Args:        cobra.ArbitraryArgs,
+		Aliases:     []string{"x"},
+		Annotations: map[string]string{lineArgsAnnotation: lineArgsAll},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Parse line numbers (convert from 1-based to 0-based)
-			indices, err := parseLineNumbers(args)
-			if err != nil {
-				return err
-			}
-
-			// Business logic - delegated to service
-			todos, err := service.ToggleTodos(indices)
+			// Parse line numbers (convert from 1-based to 0-based), or select todos by query.
+			// Done todos matching --where are left alone, so that it never reopens finished tasks
+			indices, err := selectIndices(cmd, service, args, todotxtlib.Filter{Done: "false"})
 			if err != nil {
 				return err
 			}
 
-			// Presentation logic - handled by presenter
-			for _, todo := range todos {
-				presenter.Print(todo)
+			verb := "toggle"
+			if where, _ := cmd.Flags().GetString("where"); where != "" {
+				verb = "complete"
 			}
-			return nil
+			return runBatch(cmd, service, presenter, verb, indices, service.ToggleTodos)
 		},
 	}
+
+	addBatchFlags(cmd)
+	return cmd
 }
@@ -8,7 +8,7 @@ import (
 
 func TestDoCmd_SingleTask(t *testing.T) {
 	repo, _ := setupTestRepository(t)
-	service := todotxtlib.NewTodoService(repo)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithClock(testNow))
 
 	// Test toggling a task that's not done (line 1)
 	indices, err := parseLineNumbers([]string{"1"})
@@ -24,8 +24,8 @@ func TestDoCmd_SingleTask(t *testing.T) {
 	output, err := repo.WriteToString()
 
 	expectedOutput := "(B) test todo 2 +project1 @context2\n" +
-		"x (A) test todo 1 +project2 @context1\n" +
-		"x (C) test todo 3 +project1 @context1\n"
+		"x (C) test todo 3 +project1 @context1\n" +
+		"x 2024-01-15 test todo 1 +project2 @context1\n"
 
 	if output != expectedOutput {
 		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
@@ -34,7 +34,7 @@ func TestDoCmd_SingleTask(t *testing.T) {
 
 func TestDoCmd_MultipleTask(t *testing.T) {
 	repo, _ := setupTestRepository(t)
-	service := todotxtlib.NewTodoService(repo)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithClock(testNow))
 
 	// Test toggling multiple tasks
 	indices, err := parseLineNumbers([]string{"1", "2"})
@@ -49,9 +49,9 @@ func TestDoCmd_MultipleTask(t *testing.T) {
 
 	output, err := repo.WriteToString()
 
-	expectedOutput := "x (A) test todo 1 +project2 @context1\n" +
-		"x (B) test todo 2 +project1 @context2\n" +
-		"x (C) test todo 3 +project1 @context1\n"
+	expectedOutput := "x (C) test todo 3 +project1 @context1\n" +
+		"x 2024-01-15 test todo 1 +project2 @context1\n" +
+		"x 2024-01-15 test todo 2 +project1 @context2\n"
 
 	if output != expectedOutput {
 		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
@@ -60,7 +60,7 @@ func TestDoCmd_MultipleTask(t *testing.T) {
 
 func TestDoCmd_ToggleAlreadyDone(t *testing.T) {
 	repo, _ := setupTestRepository(t)
-	service := todotxtlib.NewTodoService(repo)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithClock(testNow))
 
 	// Test toggling a task to not done
 	indices, err := parseLineNumbers([]string{"3"})
@@ -84,9 +84,27 @@ func TestDoCmd_ToggleAlreadyDone(t *testing.T) {
 	}
 }
 
+func TestDoCmd_KeepPriority(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo,
+		todotxtlib.WithClock(testNow),
+		todotxtlib.WithPriorityTag(true),
+	)
+
+	indices, err := parseLineNumbers([]string{"1"})
+	assertNoError(t, err)
+
+	todos, err := service.ToggleTodos(indices)
+	assertNoError(t, err)
+
+	if todos[0].Text != "x 2024-01-15 test todo 1 +project2 @context1 pri:A" {
+		t.Errorf("Expected completed todo to keep its priority in a tag, got '%s'", todos[0].Text)
+	}
+}
+
 func TestDoCmd_InvalidLineNumber(t *testing.T) {
 	repo, _ := setupTestRepository(t)
-	service := todotxtlib.NewTodoService(repo)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithClock(testNow))
 
 	// Test with invalid line number (too high)
 	indices, err := parseLineNumbers([]string{"10"})
@@ -112,15 +130,30 @@ func TestDoCmd_ZeroLineNumber(t *testing.T) {
 }
 
 func TestDoCmd_NegativeLineNumber(t *testing.T) {
-	// Test with negative line number
-	_, err := parseLineNumbers([]string{"-1"})
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithClock(testNow))
+
+	// Negative line numbers count back from the last line
+	indices, err := lineIndices(service, []string{"-1"})
+	assertNoError(t, err)
+
+	if len(indices) != 1 || indices[0] != 2 {
+		t.Fatalf("Expected index 2 for the last line, got %v", indices)
+	}
+
+	// Counting back past the first line fails
+	_, err = lineIndices(service, []string{"-4"})
+	assertError(t, err)
+	assertContains(t, err.Error(), "no todo on line 4 from the end")
+
+	_, err = parseLineNumbers([]string{"-0"})
 	assertError(t, err)
 	assertContains(t, err.Error(), "line number must be positive")
 }
 
 func TestDoCmd_EmptyRepository(t *testing.T) {
 	repo, _ := setupEmptyTestRepository(t)
-	service := todotxtlib.NewTodoService(repo)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithClock(testNow))
 
 	// Test toggling on empty repository
 	indices, err := parseLineNumbers([]string{"1"})
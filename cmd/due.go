@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/todotxtlib"
+	"github.com/spf13/cobra"
+)
+
+// defaultDueDays is how far ahead the due command looks when no number of days is given
+const defaultDueDays = 7
+
+// parseDueDays parses the optional [DAYS] argument of the due command
+func parseDueDays(args []string) (int, error) {
+	if len(args) == 0 {
+		return defaultDueDays, nil
+	}
+
+	days, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert arg to int: %w", err)
+	}
+	if days < 0 {
+		return 0, fmt.Errorf("number of days must not be negative, got %d", days)
+	}
+	return days, nil
+}
+
+// NewDueCmd creates a new cobra command for listing overdue and upcoming todos.
+func NewDueCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
+	return &cobra.Command{
+		Use:   "due [DAYS]",
+		Short: "List overdue todos and todos due soon",
+		Long: `Lists pending tasks with a due:YYYY-MM-DD tag that are overdue, due today, or due within the next
+[DAYS] days, ordered by due date. [DAYS] defaults to 7.
+
+# list tasks that are overdue or due within the next week
+togodo due
+
+# list tasks that are overdue or due today
+togodo due 0
+`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			days, err := parseDueDays(args)
+			if err != nil {
+				return err
+			}
+
+			// Business logic - delegated to service
+			todos, err := service.DueTodos(days)
+			if err != nil {
+				return err
+			}
+
+			// Presentation logic - handled by presenter
+			return presenter.PrintList(todos)
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/todotxtlib"
+)
+
+func TestDueCmd_DefaultDays(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithClock(testNow))
+
+	repo.Add("Task 1 due:2024-01-20")
+	repo.Add("Task 2 due:2024-01-10")
+	repo.Add("Task 3 due:2024-02-15")
+	repo.Add("Task 4 no due date")
+
+	days, err := parseDueDays([]string{})
+	assertNoError(t, err)
+
+	todos, err := service.DueTodos(days)
+	assertNoError(t, err)
+
+	output := strings.Join(cli.NewPlainFormatter().FormatList(todos), "\n")
+	expected := `  2 Task 2 due:2024-01-10
+  1 Task 1 due:2024-01-20`
+
+	if output != expected {
+		t.Errorf("Expected output:\n%s\n\nGot:\n%s", expected, output)
+	}
+}
+
+func TestDueCmd_DueToday(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithClock(testNow))
+
+	repo.Add("Task 1 due:2024-01-15")
+	repo.Add("Task 2 due:2024-01-16")
+
+	days, err := parseDueDays([]string{"0"})
+	assertNoError(t, err)
+
+	todos, err := service.DueTodos(days)
+	assertNoError(t, err)
+
+	if len(todos) != 1 || todos[0].Text != "Task 1 due:2024-01-15" {
+		t.Errorf("Expected only the task due today, got %v", todos)
+	}
+}
+
+func TestDueCmd_InvalidDays(t *testing.T) {
+	_, err := parseDueDays([]string{"abc"})
+	assertError(t, err)
+	assertContains(t, err.Error(), "failed to convert arg to int")
+
+	_, err = parseDueDays([]string{"-1"})
+	assertError(t, err)
+	assertContains(t, err.Error(), "must not be negative")
+}
@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gkarolyi/togodo/internal/config"
+	"github.com/gkarolyi/togodo/todotxtlib"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// selectedArg refers to the todos saved by pressing e in the TUI
+const selectedArg = "@selected"
+
+// maxLineRange is the most lines a range such as 3-7 may span
+const maxLineRange = 1 << 20
+
+// lineArgRe matches a line number argument, see parseLineNumbers
+var lineArgRe = regexp.MustCompile(`^(\d+(-\d+)?|-\d+|last(-\d+)?|@selected)(,(\d+(-\d+)?|-\d+|last(-\d+)?|@selected))*$`)
+
+// negativeLineRe matches a line number counting back from the end, such as -1
+var negativeLineRe = regexp.MustCompile(`^-\d+$`)
+
+// lineArgsAnnotation marks the commands taking line numbers, as either
+// lineArgsAll, or lineArgsFirst if only their first argument is a line number
+const (
+	lineArgsAnnotation = "line_args"
+	lineArgsAll        = "all"
+	lineArgsFirst      = "first"
+)
+
+// parseLineNumbers converts CLI line numbers (1-based) to repository indices (0-based)
+// Each argument is a line number, a range such as 3-7, or a comma separated list of them
+// last, last-N and negative line numbers count back from the end of the list, and are
+// returned as negative indices, -1 for the last line, for resolveIndices to look up
+func parseLineNumbers(args []string) ([]int, error) {
+	indices := []int{}
+	for _, arg := range args {
+		for _, part := range strings.Split(arg, ",") {
+			parsed, err := parseLinePart(part)
+			if err != nil {
+				return nil, err
+			}
+			indices = append(indices, parsed...)
+		}
+	}
+	return indices, nil
+}
+
+// parseLinePart converts a line number, range or line counted from the end to indices
+func parseLinePart(part string) ([]int, error) {
+	if part == "last" {
+		return []int{-1}, nil
+	}
+
+	if before, ok := strings.CutPrefix(part, "last-"); ok {
+		lineNumber, err := strconv.Atoi(before)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert arg to int: %w", err)
+		}
+		if lineNumber < 0 {
+			return nil, fmt.Errorf("line number must not be negative, got %s", part)
+		}
+		return []int{-lineNumber - 1}, nil
+	}
+
+	if strings.HasPrefix(part, "-") {
+		lineNumber, err := parseLineNumber(part[1:])
+		if err != nil {
+			return nil, err
+		}
+		return []int{-lineNumber}, nil
+	}
+
+	if from, to, ok := strings.Cut(part, "-"); ok {
+		first, err := parseLineNumber(from)
+		if err != nil {
+			return nil, err
+		}
+		last, err := parseLineNumber(to)
+		if err != nil {
+			return nil, err
+		}
+		if first > last {
+			return nil, fmt.Errorf("invalid range %s, the first line number must not be greater than the last", part)
+		}
+		if last-first >= maxLineRange {
+			return nil, fmt.Errorf("invalid range %s, ranges can span at most %d lines", part, maxLineRange)
+		}
+
+		indices := make([]int, 0, last-first+1)
+		for lineNumber := first; lineNumber <= last; lineNumber++ {
+			indices = append(indices, lineNumber-1)
+		}
+		return indices, nil
+	}
+
+	lineNumber, err := parseLineNumber(part)
+	if err != nil {
+		return nil, err
+	}
+	return []int{lineNumber - 1}, nil // Convert to 0-based
+}
+
+// parseLineNumber converts a single positive line number
+func parseLineNumber(text string) (int, error) {
+	lineNumber, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert arg to int: %w", err)
+	}
+	if lineNumber < 1 {
+		return 0, fmt.Errorf("line number must be positive, got %d", lineNumber)
+	}
+	return lineNumber, nil
+}
+
+// isLineArg reports whether an argument is a line number argument, see parseLineNumbers
+func isLineArg(arg string) bool {
+	return lineArgRe.MatchString(arg)
+}
+
+// RewriteLineArgs rewrites line numbers counting back from the end, such as -1, in
+// the arguments of commands taking line numbers to the equivalent last or last-N,
+// so that they are not parsed as flags: togodo do -1 runs as togodo do last, and
+// togodo do -3 as togodo do last-2. Arguments after -- are left as they are
+func RewriteLineArgs(rootCmd *cobra.Command, args []string) []string {
+	cmd, _, err := rootCmd.Find(args)
+	if err != nil || cmd.Annotations[lineArgsAnnotation] == "" {
+		return args
+	}
+	onlyFirst := cmd.Annotations[lineArgsAnnotation] == lineArgsFirst
+
+	// The first positional arguments are the names of the command and its parents
+	commandNames := len(strings.Fields(cmd.CommandPath())) - 1
+	rewritten := slices.Clone(args)
+	positional := 0
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return rewritten
+
+		case strings.HasPrefix(arg, "--"):
+			name, _, hasValue := strings.Cut(arg[2:], "=")
+			if !hasValue && flagTakesValue(cmd, name, false) {
+				i++
+			}
+
+		case strings.HasPrefix(arg, "-") && len(arg) > 1 && !isNegativeLineArg(arg):
+			shorthands := arg[1:]
+			for j, shorthand := range shorthands {
+				if flagTakesValue(cmd, string(shorthand), true) {
+					if j == len(shorthands)-1 {
+						i++
+					}
+					break
+				}
+			}
+
+		default:
+			if positional >= commandNames && isNegativeLineArg(arg) && (!onlyFirst || positional == commandNames) {
+				rewritten[i] = countFromLast(arg)
+			}
+			positional++
+		}
+	}
+	return rewritten
+}
+
+// isNegativeLineArg reports whether an argument is a line number argument that
+// starts with a line number counting back from the end, and so looks like a flag
+func isNegativeLineArg(arg string) bool {
+	first, _, _ := strings.Cut(arg, ",")
+	return negativeLineRe.MatchString(first) && isLineArg(arg)
+}
+
+// countFromLast rewrites the line numbers counting back from the end in a line
+// number argument from -N to last-(N-1)
+func countFromLast(arg string) string {
+	parts := strings.Split(arg, ",")
+	for i, part := range parts {
+		if !negativeLineRe.MatchString(part) {
+			continue
+		}
+		lineNumber, err := strconv.Atoi(part[1:])
+		if err != nil || lineNumber < 1 {
+			continue // left for parseLineNumbers to report
+		}
+		parts[i] = "last"
+		if lineNumber > 1 {
+			parts[i] = fmt.Sprintf("last-%d", lineNumber-1)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// flagTakesValue reports whether a flag of the command, given by name or
+// shorthand, takes a value as the next argument
+func flagTakesValue(cmd *cobra.Command, name string, shorthand bool) bool {
+	lookup := func(flags *pflag.FlagSet) *pflag.Flag {
+		if shorthand {
+			return flags.ShorthandLookup(name)
+		}
+		return flags.Lookup(name)
+	}
+
+	flag := lookup(cmd.Flags())
+	if flag == nil {
+		flag = lookup(cmd.InheritedFlags())
+	}
+	return flag != nil && flag.NoOptDefVal == ""
+}
+
+// lineIndices converts CLI line numbers to repository indices (0-based), see
+// parseLineNumbers, expanding @selected and checking that every line exists
+// before anything is changed
+func lineIndices(service todotxtlib.TodoService, args []string) ([]int, error) {
+	args, err := expandSelected(service, args)
+	if err != nil {
+		return nil, err
+	}
+
+	indices, err := parseLineNumbers(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveIndices(service, indices)
+}
+
+// lineIndex converts a CLI line number to a repository index (0-based), as lineIndices
+// does for commands that change a single todo
+func lineIndex(service todotxtlib.TodoService, arg string) (int, error) {
+	indices, err := lineIndices(service, []string{arg})
+	if err != nil {
+		return 0, err
+	}
+	if len(indices) != 1 {
+		return 0, fmt.Errorf("expected a single line number, got %s", arg)
+	}
+	return indices[0], nil
+}
+
+// resolveIndices turns negative indices counting back from the end of the list
+// into positions in it, and checks that every index has a todo. Repeated indices
+// are dropped, so that e.g. do 1,1 does not toggle line 1 twice
+func resolveIndices(service todotxtlib.TodoService, indices []int) ([]int, error) {
+	todos, err := service.FilterTodos(todotxtlib.Filter{})
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make([]int, 0, len(indices))
+	for _, index := range indices {
+		if index < 0 {
+			if -index > len(todos) {
+				return nil, fmt.Errorf("no todo on line %d from the end, there are %s", -index, countTodos(len(todos)))
+			}
+			index += len(todos)
+		}
+		if index >= len(todos) {
+			return nil, fmt.Errorf("no todo on line %d, there are %s", index+1, countTodos(len(todos)))
+		}
+		if !slices.Contains(resolved, index) {
+			resolved = append(resolved, index)
+		}
+	}
+	return resolved, nil
+}
+
+// expandSelected replaces @selected in line number arguments with the line
+// numbers of the todos saved by the TUI, see config.GetSelectionPath
+func expandSelected(service todotxtlib.TodoService, args []string) ([]string, error) {
+	var selected string
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		parts := strings.Split(arg, ",")
+		for j, part := range parts {
+			if part != selectedArg {
+				continue
+			}
+			if selected == "" {
+				lineNumbers, err := selectedLineNumbers(service)
+				if err != nil {
+					return nil, err
+				}
+				selected = strings.Join(lineNumbers, ",")
+			}
+			parts[j] = selected
+		}
+		expanded[i] = strings.Join(parts, ",")
+	}
+	return expanded, nil
+}
+
+// selectedLineNumbers returns the line numbers of the todos saved by the TUI.
+// The selection is saved as the text of the todos, so that it still refers to
+// the same todos after the list has been sorted
+func selectedLineNumbers(service todotxtlib.TodoService) ([]string, error) {
+	content, err := os.ReadFile(config.GetSelectionPath())
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no todos selected, press e in the TUI to save the selected todos as %s", selectedArg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read selected todos: %w", err)
+	}
+
+	todos, err := service.FilterTodos(todotxtlib.Filter{})
+	if err != nil {
+		return nil, err
+	}
+
+	lineNumbers := []string{}
+	used := make(map[int]bool)
+	for _, text := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		index := slices.IndexFunc(todos, func(todo todotxtlib.Todo) bool {
+			return todo.Text == text && !used[todo.LineNumber]
+		})
+		if index < 0 {
+			return nil, fmt.Errorf("selected todo %q is no longer in todo.txt, select it again in the TUI", text)
+		}
+		used[todos[index].LineNumber] = true
+		lineNumbers = append(lineNumbers, strconv.Itoa(todos[index].LineNumber))
+	}
+
+	if len(lineNumbers) == 0 {
+		return nil, fmt.Errorf("no todos selected, press e in the TUI to save the selected todos as %s", selectedArg)
+	}
+	return lineNumbers, nil
+}
+
+// queryIndices returns the repository indices (0-based) of the todos matching both a
+// query, see todotxtlib.ParseQuery, and the given filter
+func queryIndices(service todotxtlib.TodoService, query string, filter todotxtlib.Filter) ([]int, error) {
+	parsed, err := todotxtlib.ParseQuery(query, queryOptions(false)...)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Query = parsed
+	todos, err := service.FilterTodos(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]int, len(todos))
+	for i, todo := range todos {
+		indices[i] = todo.LineNumber - 1
+	}
+	return indices, nil
+}
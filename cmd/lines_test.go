@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/todotxtlib"
+	"github.com/spf13/viper"
+)
+
+func TestParseLineNumbers(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    []int
+		wantErr string
+	}{
+		{args: []string{"1", "3"}, want: []int{0, 2}},
+		{args: []string{"3-5"}, want: []int{2, 3, 4}},
+		{args: []string{"1,4,9-11"}, want: []int{0, 3, 8, 9, 10}},
+		{args: []string{"2-2"}, want: []int{1}},
+		{args: []string{"last"}, want: []int{-1}},
+		{args: []string{"last-0,last-2"}, want: []int{-1, -3}},
+		{args: []string{"-2", "1"}, want: []int{-2, 0}},
+		{args: []string{"5-3"}, wantErr: "invalid range 5-3"},
+		{args: []string{"0-3"}, wantErr: "line number must be positive"},
+		{args: []string{"1-last"}, wantErr: "failed to convert arg to int"},
+		{args: []string{"last-x"}, wantErr: "failed to convert arg to int"},
+		{args: []string{"1,,2"}, wantErr: "failed to convert arg to int"},
+		{args: []string{"1-99999999"}, wantErr: "ranges can span at most"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseLineNumbers(tt.args)
+		if tt.wantErr != "" {
+			assertError(t, err)
+			assertContains(t, err.Error(), tt.wantErr)
+			continue
+		}
+		assertNoError(t, err)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("parseLineNumbers(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestLineIndices(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	tests := []struct {
+		args    []string
+		want    []int
+		wantErr string
+	}{
+		{args: []string{"last"}, want: []int{2}},
+		{args: []string{"-3,last"}, want: []int{0, 2}},
+		{args: []string{"1-3", "2"}, want: []int{0, 1, 2}},
+		{args: []string{"2-4"}, wantErr: "no todo on line 4, there are 3 todos"},
+		{args: []string{"-4"}, wantErr: "no todo on line 4 from the end"},
+	}
+
+	for _, tt := range tests {
+		got, err := lineIndices(service, tt.args)
+		if tt.wantErr != "" {
+			assertError(t, err)
+			assertContains(t, err.Error(), tt.wantErr)
+			continue
+		}
+		assertNoError(t, err)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("lineIndices(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestIsLineArg(t *testing.T) {
+	for _, arg := range []string{"1", "3-7", "1,4,9-12", "last", "last-2", "-1", "@selected", "2,@selected"} {
+		if !isLineArg(arg) {
+			t.Errorf("isLineArg(%q) = false, want true", arg)
+		}
+	}
+	for _, arg := range []string{"+project", "@context", "due:2024-01-01", "1,", "lastly", "-"} {
+		if isLineArg(arg) {
+			t.Errorf("isLineArg(%q) = true, want false", arg)
+		}
+	}
+}
+
+func TestRewriteLineArgs(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+	rootCmd := NewRootCmd(service, service, cli.NewPresenter(), "")
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"do", "-1"}, want: []string{"do", "last"}},
+		{args: []string{"x", "-3", "1"}, want: []string{"x", "last-2", "1"}},
+		{args: []string{"do", "-1,-2,3"}, want: []string{"do", "last,last-1,3"}},
+		{args: []string{"do", "--dry-run", "-2"}, want: []string{"do", "--dry-run", "last-1"}},
+		{args: []string{"pri", "-1", "B"}, want: []string{"pri", "last", "B"}},
+		{args: []string{"-f", "todo.txt", "rm", "-1"}, want: []string{"-f", "todo.txt", "rm", "last"}},
+		{args: []string{"do", "-n", "-1"}, want: []string{"do", "-n", "last"}},
+		{args: []string{"do", "--", "-1"}, want: []string{"do", "--", "-1"}},
+		{args: []string{"do", "-0"}, want: []string{"do", "-0"}},
+		// Only the first argument of append is a line number, the rest is text
+		{args: []string{"append", "-1", "-5", "degrees"}, want: []string{"append", "last", "-5", "degrees"}},
+		// The value of a flag is not a line number
+		{args: []string{"tag", "--where", "-1", "+x"}, want: []string{"tag", "--where", "-1", "+x"}},
+		{args: []string{"tag", "-w", "-1", "+x"}, want: []string{"tag", "-w", "-1", "+x"}},
+		{args: []string{"add", "-1"}, want: []string{"add", "-1"}},
+	}
+
+	for _, tt := range tests {
+		got := RewriteLineArgs(rootCmd, tt.args)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("RewriteLineArgs(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestDoCmd_NegativeLineNumberWithoutSeparator(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithClock(testNow))
+	rootCmd := NewRootCmd(service, service, cli.NewPresenter(), "")
+
+	rootCmd.SetArgs(RewriteLineArgs(rootCmd, []string{"do", "-2"}))
+	assertNoError(t, rootCmd.Execute())
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1\n" +
+		"x (C) test todo 3 +project1 @context1\n" +
+		"x 2024-01-15 test todo 2 +project1 @context2\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestLineIndices_Selected(t *testing.T) {
+	dir := t.TempDir()
+	viper.Set("todo_txt_path", filepath.Join(dir, "todo.txt"))
+	t.Cleanup(viper.Reset)
+
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	_, err := lineIndices(service, []string{"@selected"})
+	assertError(t, err)
+	assertContains(t, err.Error(), "no todos selected")
+
+	selection := "x (C) test todo 3 +project1 @context1\n(A) test todo 1 +project2 @context1\n"
+	if err := os.WriteFile(filepath.Join(dir, "todo.txt.selected"), []byte(selection), 0644); err != nil {
+		t.Fatalf("failed to write selection: %v", err)
+	}
+
+	indices, err := lineIndices(service, []string{"@selected,2"})
+	assertNoError(t, err)
+	if !slices.Equal(indices, []int{2, 0, 1}) {
+		t.Errorf("lineIndices(@selected,2) = %v, want [2 0 1]", indices)
+	}
+
+	// A selected todo that has changed since is reported
+	_, err = service.ReplaceTodo(0, "(A) changed todo")
+	assertNoError(t, err)
+
+	_, err = lineIndices(service, []string{"@selected"})
+	assertError(t, err)
+	assertContains(t, err.Error(), "is no longer in todo.txt")
+}
+
+func TestDoCmd_OutOfRangeChangesNothing(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithClock(testNow))
+
+	cmd := NewDoCmd(service, cli.NewPresenter())
+	err := cmd.RunE(cmd, []string{"1-2", "9"})
+	assertError(t, err)
+	assertContains(t, err.Error(), "no todo on line 9")
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+	if output != unchangedTestOutput {
+		t.Errorf("Expected nothing to change, got:\n%s", output)
+	}
+}
+
+func TestPriCmd_Range(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	cmd := NewPriCmd(service, cli.NewPresenter())
+	assertNoError(t, cmd.RunE(cmd, []string{"1-2", "D"}))
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(D) test todo 1 +project2 @context1\n" +
+		"(D) test todo 2 +project1 @context2\n" +
+		"x (C) test todo 3 +project1 @context1\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
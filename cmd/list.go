@@ -1,41 +1,210 @@
 package cmd
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/internal/config"
 	"github.com/gkarolyi/togodo/todotxtlib"
 	"github.com/spf13/cobra"
 )
 
 // NewListCmd creates a new cobra command for listing todos.
 func NewListCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "list [FILTER]",
 		Short: "List and filter items in your todo.txt",
-		Long: `Lists tasks sorted in order of priority, with done items at the bottom of the list. Tasks can optionally be filtered
-by passing an optional [FILTER] argument. If no filter is passed, list shows all items in your todo.txt file. Tasks are shown
-with a line number to allow you to easily refer to them. For example:
+		Long: `Lists tasks in the order they appear in your todo.txt file, or in the order given by --sort. Tasks can
+optionally be filtered by passing an optional [FILTER] query. If no filter is passed, list shows all items in your
+todo.txt file. Tasks are shown with their line number in your todo.txt file, which stays the same when filtering or
+sorting, to allow you to easily refer to them. Blank lines are not counted, and are removed the next time togodo saves
+your todo.txt file. Tasks with a t:YYYY-MM-DD threshold date in the future are hidden unless --all is passed. Results
+can be sorted with --sort, which takes a comma separated list of fields (text, priority, due, created, completed,
+project, context, line, done or tag:KEY), each optionally prefixed with - to sort in descending order and suffixed with
+:first to show tasks without a value first. priority lists (A) first, so -priority lists the least important tasks
+first. The sort config key sets a default.
+
+A filter is a list of terms that must all match, unless joined by OR. NOT or a leading - excludes a term, and
+parentheses group terms. Terms can be a @context or +project, a priority such as (A) or a range such as pri:A..C,
+x for done tasks, a date comparison such as due<today+7d or created>=2024-01-01 (on due, t, created or completed),
+a tag such as rec:1w or due:2024-* with * wildcards, a /regular expression/, or text, with "quotes" for phrases.
+Tasks can also be filtered with flags: --project and --context list tasks in all of the given projects or contexts,
+--any-project and --any-context in at least one of them, and --no-project and --no-context in none of them. Each flag
+can be repeated or take a comma separated list. --priority takes a list of priorities, and --tag and --no-tag take
+key:value tags, or keys to match a tag with any value.
+
+Views configured in the config file can be listed with --view. A view is a [views.NAME] table with a query and an
+optional sort, for example:
+
+[views.today]
+query = "due<=today -x"
+sort = "due,priority"
+
+A [FILTER] narrows the view down further, and --sort overrides its sort.
+
+Matching ignores case, unless smart_case is set in the config and the term contains upper case letters. With --fuzzy,
+text matches if its characters appear in order, and the best matches are listed first unless --sort is passed. For
+example:
 
 # list all items in your todo.txt file
 togodo list
 
-# list all items in your todo.txt file that contain the string '@work'
+# list all items in the @work context
 togodo list '@work'
+
+# list items in +release that are due within a week and are not @home
+togodo list '+release due<today+7d -@home'
+
+# list important items for either project
+togodo list 'pri:A..B (+web OR +api)'
+
+# list items in both +release and @review, except those waiting on someone
+togodo list --project release --context review --no-tag waiting
+
+# list items in either @phone or @errands
+togodo list --any-context phone,errands
+
+# list the items of the today view, as configured in a [views.today] table
+togodo list --view today
+
+# list all items, including tasks with a future threshold date
+togodo list --all
+
+# list items by due date, most important first when due on the same day
+togodo list --sort due,priority
 `,
 		Aliases: []string{"ls", "l"},
 		Args:    cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			searchQuery := strings.Join(args, " ")
+			all, _ := cmd.Flags().GetBool("all")
+			fuzzy, _ := cmd.Flags().GetBool("fuzzy")
+			sortSpec, _ := cmd.Flags().GetString("sort")
+			viewName, _ := cmd.Flags().GetString("view")
+			viewSort := ""
+
+			query, err := todotxtlib.ParseQuery(searchQuery, queryOptions(fuzzy)...)
+			if err != nil {
+				return err
+			}
+
+			if viewName != "" {
+				view, err := config.GetView(viewName)
+				if err != nil {
+					return err
+				}
+				viewQuery, err := todotxtlib.ParseQuery(view.Query, queryOptions(fuzzy)...)
+				if err != nil {
+					return fmt.Errorf("invalid query in view %q: %w", view.Name, err)
+				}
+				query = todotxtlib.AllOf(viewQuery, query)
+				viewSort = view.Sort
+			}
+			ranking := fuzzy && strings.TrimSpace(searchQuery) != ""
+			sortSpec = listSort(sortSpec, viewSort, config.GetSort(), ranking)
+
+			filter := todotxtlib.Filter{
+				Query:      query,
+				HideFuture: !all,
+				Rank:       ranking,
+				Priorities: todotxtlib.Set{Any: flagValues(cmd, "priority", "")},
+				Projects: todotxtlib.Set{
+					All:  flagValues(cmd, "project", "+"),
+					Any:  flagValues(cmd, "any-project", "+"),
+					None: flagValues(cmd, "no-project", "+"),
+				},
+				Contexts: todotxtlib.Set{
+					All:  flagValues(cmd, "context", "@"),
+					Any:  flagValues(cmd, "any-context", "@"),
+					None: flagValues(cmd, "no-context", "@"),
+				},
+				Tags: todotxtlib.Set{
+					All:  flagValues(cmd, "tag", ""),
+					None: flagValues(cmd, "no-tag", ""),
+				},
+			}
+			if config.GetSmartCase() {
+				filter.Case = todotxtlib.SmartCase
+			}
 
 			// Business logic - delegated to service
-			todos, err := service.SearchTodos(searchQuery)
+			todos, err := service.FilterTodos(filter)
 			if err != nil {
 				return err
 			}
 
+			if sortSpec != "" {
+				sort, err := todotxtlib.ParseSort(sortSpec)
+				if err != nil {
+					return err
+				}
+				sort.Apply(todos)
+			}
+
 			// Presentation logic - handled by presenter
 			return presenter.PrintList(todos)
 		},
 	}
+
+	cmd.Flags().BoolP("all", "a", false, "Include tasks with a threshold date in the future")
+	cmd.Flags().StringP("sort", "s", "", "Sort by a comma separated list of fields, e.g. due,priority")
+	cmd.Flags().String("view", "", "List the tasks of a view from the config file, further filtered by [FILTER]")
+	cmd.Flags().Bool("fuzzy", false, "Match text fuzzily and list the best matches first")
+	cmd.Flags().StringSliceP("project", "p", nil, "Only list tasks in all of these projects")
+	cmd.Flags().StringSlice("any-project", nil, "Only list tasks in at least one of these projects")
+	cmd.Flags().StringSlice("no-project", nil, "Only list tasks in none of these projects")
+	cmd.Flags().StringSliceP("context", "c", nil, "Only list tasks in all of these contexts")
+	cmd.Flags().StringSlice("any-context", nil, "Only list tasks in at least one of these contexts")
+	cmd.Flags().StringSlice("no-context", nil, "Only list tasks in none of these contexts")
+	cmd.Flags().StringSlice("priority", nil, "Only list tasks with one of these priorities")
+	cmd.Flags().StringSlice("tag", nil, "Only list tasks with all of these key:value tags, or tags with these keys")
+	cmd.Flags().StringSlice("no-tag", nil, "Only list tasks with none of these key:value tags, or tags with these keys")
+
+	return cmd
+}
+
+// flagValues returns the values of a repeatable flag, adding the prefix to
+// values without it so that e.g. both work and @work name the @work context
+func flagValues(cmd *cobra.Command, name, prefix string) []string {
+	values, _ := cmd.Flags().GetStringSlice(name)
+	return withPrefix(values, prefix)
+}
+
+// withPrefix adds the prefix to values without it, e.g. to turn work into @work
+func withPrefix(values []string, prefix string) []string {
+	prefixed := make([]string, len(values))
+	for i, value := range values {
+		if !strings.HasPrefix(value, prefix) {
+			value = prefix + value
+		}
+		prefixed[i] = value
+	}
+	return prefixed
+}
+
+// listSort returns the sort to list todos in: the one passed with --sort, or else
+// the sort of the view or the config. Fuzzy search results are listed best match
+// first, so they are only sorted with --sort
+func listSort(flagSort, viewSort, configSort string, ranking bool) string {
+	switch {
+	case flagSort != "":
+		return flagSort
+	case ranking:
+		return ""
+	case viewSort != "":
+		return viewSort
+	default:
+		return configSort
+	}
+}
+
+// queryOptions returns the options for parsing search queries, ignoring case
+// unless the smart_case config is set
+func queryOptions(fuzzy bool) []todotxtlib.QueryOption {
+	mode := todotxtlib.IgnoreCase
+	if config.GetSmartCase() {
+		mode = todotxtlib.SmartCase
+	}
+	return []todotxtlib.QueryOption{todotxtlib.WithCaseMode(mode), todotxtlib.WithFuzzy(fuzzy)}
 }
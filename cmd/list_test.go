@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/todotxtlib"
 )
 
 func TestExecuteList_AllTasks(t *testing.T) {
@@ -28,7 +32,7 @@ func TestExecuteList_FilterByContext(t *testing.T) {
 	assertNoError(t, err)
 
 	expected := `  1 (A) test todo 1 +project2 @context1
-  2 x (C) test todo 3 +project1 @context1`
+  3 x (C) test todo 3 +project1 @context1`
 
 	if output != expected {
 		t.Errorf("Expected output:\n%s\n\nGot:\n%s", expected, output)
@@ -42,8 +46,8 @@ func TestExecuteList_FilterByProject(t *testing.T) {
 	output, err := executeListForTest(repo, "+project1")
 	assertNoError(t, err)
 
-	expected := `  1 (B) test todo 2 +project1 @context2
-  2 x (C) test todo 3 +project1 @context1`
+	expected := `  2 (B) test todo 2 +project1 @context2
+  3 x (C) test todo 3 +project1 @context1`
 
 	if output != expected {
 		t.Errorf("Expected output:\n%s\n\nGot:\n%s", expected, output)
@@ -149,7 +153,7 @@ func TestExecuteList_FilterDoneTasks(t *testing.T) {
 	output, err := executeListForTest(repo, "x ")
 	assertNoError(t, err)
 
-	expected := `  1 x (C) test todo 3 +project1 @context1`
+	expected := `  3 x (C) test todo 3 +project1 @context1`
 
 	if output != expected {
 		t.Errorf("Expected output:\n%s\n\nGot:\n%s", expected, output)
@@ -182,7 +186,7 @@ func TestExecuteList_FilterByDueDate(t *testing.T) {
 	repo.Add("Task 3 no due date")
 
 	// Test filtering by due date
-	output, err := executeListForTest(repo, "due:2024")
+	output, err := executeListForTest(repo, "due:2024-*")
 	assertNoError(t, err)
 
 	expected := `  1 Task 1 due:2024-12-31
@@ -200,11 +204,13 @@ func TestExecuteList_WhitespaceInFilter(t *testing.T) {
 	output, err := executeListForTest(repo, "  test todo  ")
 	assertNoError(t, err)
 
-	// Should find no matches since the exact string "  test todo  " doesn't exist
-	expected := ``
+	// Surrounding whitespace separates terms like any other
+	expected := `  1 (A) test todo 1 +project2 @context1
+  2 (B) test todo 2 +project1 @context2
+  3 x (C) test todo 3 +project1 @context1`
 
 	if output != expected {
-		t.Errorf("Expected empty output, got:\n%s", output)
+		t.Errorf("Expected output:\n%s\n\nGot:\n%s", expected, output)
 	}
 }
 
@@ -256,8 +262,8 @@ func TestExecuteList_Integration(t *testing.T) {
 	output, err := executeListForTest(repo, "+project1")
 	assertNoError(t, err)
 
-	expected := `  1 (B) test todo 2 +project1 @context2
-  2 x (C) test todo 3 +project1 @context1`
+	expected := `  2 (B) test todo 2 +project1 @context2
+  3 x (C) test todo 3 +project1 @context1`
 
 	if output != expected {
 		t.Errorf("Expected output:\n%s\n\nGot:\n%s", expected, output)
@@ -277,11 +283,201 @@ func TestExecuteList_ErrorHandling(t *testing.T) {
 		"\t", // tab
 		"@",  // incomplete context
 		"+",  // incomplete project
-		"(",  // incomplete priority
 	}
 
 	for _, input := range problematicInputs {
 		_, err := executeListForTest(repo, input)
 		assertNoError(t, err) // Should not error, just return filtered results
 	}
+
+	// Incomplete queries are reported as errors
+	invalidInputs := []string{
+		"(",
+		"+project1 OR",
+		"(@context1",
+		"pri:1",
+		"due<soon",
+	}
+
+	for _, input := range invalidInputs {
+		_, err := executeListForTest(repo, input)
+		assertError(t, err)
+	}
+}
+
+func TestExecuteList_Query(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "terms match anywhere",
+			query:    "@context1 +project1",
+			expected: `  3 x (C) test todo 3 +project1 @context1`,
+		},
+		{
+			name:  "or",
+			query: "@context2 OR (A)",
+			expected: `  1 (A) test todo 1 +project2 @context1
+  2 (B) test todo 2 +project1 @context2`,
+		},
+		{
+			name:     "exclusion",
+			query:    "-@context1",
+			expected: `  2 (B) test todo 2 +project1 @context2`,
+		},
+		{
+			name:     "not with group",
+			query:    "NOT (+project2 OR x)",
+			expected: `  2 (B) test todo 2 +project1 @context2`,
+		},
+		{
+			name:  "priority range",
+			query: "pri:A..B",
+			expected: `  1 (A) test todo 1 +project2 @context1
+  2 (B) test todo 2 +project1 @context2`,
+		},
+		{
+			name:     "case insensitive phrase",
+			query:    `"TEST TODO 2"`,
+			expected: `  2 (B) test todo 2 +project1 @context2`,
+		},
+		{
+			name:  "regex",
+			query: "/todo [13]/",
+			expected: `  1 (A) test todo 1 +project2 @context1
+  3 x (C) test todo 3 +project1 @context1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, _ := setupTestRepository(t)
+
+			output, err := executeListForTest(repo, tt.query)
+			assertNoError(t, err)
+
+			if output != tt.expected {
+				t.Errorf("Expected output:\n%s\n\nGot:\n%s", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestExecuteList_HidesFutureThreshold(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+
+	repo.Add("Task 1 t:2000-01-01")
+	repo.Add("Task 2 t:2999-01-01")
+
+	output, err := executeListForTest(repo, "")
+	assertNoError(t, err)
+
+	expected := `  1 Task 1 t:2000-01-01`
+
+	if output != expected {
+		t.Errorf("Expected output:\n%s\n\nGot:\n%s", expected, output)
+	}
+}
+
+func TestExecuteList_Sort(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+
+	repo.Add("(B) Task 1 due:2024-01-20")
+	repo.Add("Task 2")
+	repo.Add("(A) Task 3 due:2024-01-20")
+	repo.Add("(C) Task 4 due:2024-01-10")
+
+	todos, err := todotxtlib.NewTodoService(repo).SearchTodos("")
+	assertNoError(t, err)
+
+	sort, err := todotxtlib.ParseSort("due,priority")
+	assertNoError(t, err)
+	sort.Apply(todos)
+
+	output := strings.Join(cli.NewPlainFormatter().FormatList(todos), "\n")
+	expected := `  4 (C) Task 4 due:2024-01-10
+  3 (A) Task 3 due:2024-01-20
+  1 (B) Task 1 due:2024-01-20
+  2 Task 2`
+
+	if output != expected {
+		t.Errorf("Expected output:\n%s\n\nGot:\n%s", expected, output)
+	}
+
+	// Sorting the results must not reorder the repository
+	all, err := repo.ListAll()
+	assertNoError(t, err)
+	if all[0].Text != "(B) Task 1 due:2024-01-20" {
+		t.Errorf("Expected repository order to be unchanged, got first todo %q", all[0].Text)
+	}
+}
+
+func TestExecuteList_InvalidSort(t *testing.T) {
+	_, err := todotxtlib.ParseSort("due,bogus")
+	assertError(t, err)
+}
+
+func TestExecuteList_Fuzzy(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+
+	repo.Add("Buy milk")
+	repo.Add("Call the mechanic about brakes")
+	repo.Add("Make a shopping list")
+
+	query, err := todotxtlib.ParseQuery("bk", todotxtlib.WithFuzzy(true))
+	assertNoError(t, err)
+	todos, err := todotxtlib.NewTodoService(repo).FilterTodos(todotxtlib.Filter{Query: query, Rank: true})
+	assertNoError(t, err)
+
+	// "brakes" has the characters closer together than "Buy milk"
+	output := strings.Join(cli.NewPlainFormatter().FormatList(todos), "\n")
+	expected := `  2 Call the mechanic about brakes
+  1 Buy milk`
+
+	if output != expected {
+		t.Errorf("Expected output:\n%s\n\nGot:\n%s", expected, output)
+	}
+}
+
+func TestFlagValues(t *testing.T) {
+	cmd := NewListCmd(nil, nil)
+	err := cmd.ParseFlags([]string{"--context", "work,@home", "--context", "phone", "--any-project", "release"})
+	assertNoError(t, err)
+
+	contexts := flagValues(cmd, "context", "@")
+	if strings.Join(contexts, " ") != "@work @home @phone" {
+		t.Errorf("Expected contexts @work @home @phone, got %v", contexts)
+	}
+	projects := flagValues(cmd, "any-project", "+")
+	if strings.Join(projects, " ") != "+release" {
+		t.Errorf("Expected projects +release, got %v", projects)
+	}
+	if tags := flagValues(cmd, "tag", ""); len(tags) != 0 {
+		t.Errorf("Expected no tags, got %v", tags)
+	}
+}
+
+func TestListSort(t *testing.T) {
+	tests := []struct {
+		name       string
+		flagSort   string
+		viewSort   string
+		configSort string
+		ranking    bool
+		want       string
+	}{
+		{name: "config", configSort: "due", want: "due"},
+		{name: "view over config", viewSort: "priority", configSort: "due", want: "priority"},
+		{name: "flag over view", flagSort: "text", viewSort: "priority", configSort: "due", want: "text"},
+		{name: "ranking ignores view and config", viewSort: "priority", configSort: "due", ranking: true, want: ""},
+		{name: "ranking with flag", flagSort: "text", configSort: "due", ranking: true, want: "text"},
+	}
+
+	for _, tt := range tests {
+		if got := listSort(tt.flagSort, tt.viewSort, tt.configSort, tt.ranking); got != tt.want {
+			t.Errorf("%s: listSort() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
 }
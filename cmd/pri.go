@@ -28,38 +28,65 @@ func parsePriorityArgs(args []string) ([]int, string, error) {
 
 // NewPriCmd creates a new cobra command for setting priority.
 func NewPriCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
-	return &cobra.Command{
-		Use:   "pri [LINE NUMBER]...",
+	cmd := &cobra.Command{
+		Use:   "pri [LINE NUMBER]... PRIORITY",
 		Short: "Set the priority of a todo item",
-		Long: `Set the priority of a todo item.
+		Long: `Set the priority of a todo item. Instead of line numbers, --where sets the priority of every task matching a
+query as used by list. --dry-run prints the tasks that would be changed, and changing more tasks matching --where than
+the confirm_threshold config (10 by default) asks for confirmation unless --yes is passed.
 
 # set the priority of the todo on line 1 to A
 togodo pri 1 A
 
 # set the priority of the todos on lines 1, 2, and 3 to B
 togodo pri 1 2 3 B
+
+# set the priority of the todos on lines 1, 4 and 9 to 12 to B
+togodo pri 1,4,9-12 B
+
+# set the priority of every overdue todo to A
+togodo pri --where 'due<today -x' A
 `,
 
-		Args:    cobra.MinimumNArgs(2),
-		Aliases: []string{"p"},
+		Args:        cobra.MinimumNArgs(1),
+		Aliases:     []string{"p"},
+		Annotations: map[string]string{lineArgsAnnotation: lineArgsAll},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Parse priority arguments
-			indices, priority, err := parsePriorityArgs(args)
+			var indices []int
+			var priority string
+			var err error
+
+			if where, _ := cmd.Flags().GetString("where"); where != "" {
+				// The priority is the only argument, todos are selected by query
+				priority = args[len(args)-1]
+				indices, err = selectIndices(cmd, service, args[:len(args)-1], todotxtlib.Filter{})
+			} else {
+				// Parse priority arguments, checking that every line exists before changing any
+				args, err = expandSelected(service, args)
+				if err != nil {
+					return err
+				}
+				indices, priority, err = parsePriorityArgs(args)
+				if err != nil {
+					return err
+				}
+				indices, err = resolveIndices(service, indices)
+			}
 			if err != nil {
 				return err
 			}
-
-			// Business logic - delegated to service
-			todos, err := service.SetPriorities(indices, priority)
+			// Reject an invalid priority before listing or confirming the todos
+			priority, err = todotxtlib.ParsePriority(priority)
 			if err != nil {
 				return err
 			}
 
-			// Presentation logic - handled by presenter
-			for _, todo := range todos {
-				presenter.Print(todo)
-			}
-			return nil
+			return runBatch(cmd, service, presenter, "set the priority of", indices, func(indices []int) ([]todotxtlib.Todo, error) {
+				return service.SetPriorities(indices, priority)
+			})
 		},
 	}
+
+	addBatchFlags(cmd)
+	return cmd
 }
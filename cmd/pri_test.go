@@ -3,6 +3,7 @@ package cmd
 import (
 	"testing"
 
+	"github.com/gkarolyi/togodo/internal/cli"
 	"github.com/gkarolyi/togodo/todotxtlib"
 )
 
@@ -112,10 +113,29 @@ func TestPriCmd_ZeroLineNumber(t *testing.T) {
 }
 
 func TestPriCmd_NegativeLineNumber(t *testing.T) {
-	// Test with negative line number
-	_, _, err := parsePriorityArgs([]string{"-1", "A"})
-	assertError(t, err)
-	assertContains(t, err.Error(), "line number must be positive")
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	// Negative line numbers count back from the last line
+	indices, priority, err := parsePriorityArgs([]string{"-2", "A"})
+	assertNoError(t, err)
+
+	indices, err = resolveIndices(service, indices)
+	assertNoError(t, err)
+
+	_, err = service.SetPriorities(indices, priority)
+	assertNoError(t, err)
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1\n" +
+		"(A) test todo 2 +project1 @context2\n" +
+		"x (C) test todo 3 +project1 @context1\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
 }
 
 func TestPriCmd_EmptyRepository(t *testing.T) {
@@ -164,3 +184,19 @@ func TestPriCmd_DoneTaskPriority(t *testing.T) {
 		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
 	}
 }
+
+func TestPriCmd_InvalidPriority(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	cmd := NewPriCmd(service, cli.NewPresenter())
+	err := cmd.RunE(cmd, []string{"1", "foo"})
+	assertError(t, err)
+	assertContains(t, err.Error(), `invalid priority "foo"`)
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+	if output != unchangedTestOutput {
+		t.Errorf("Expected nothing to change, got:\n%s", output)
+	}
+}
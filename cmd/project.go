@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/todotxtlib"
+	"github.com/spf13/cobra"
+)
+
+// NewProjectCmd creates a new cobra command for renaming and merging projects.
+func NewProjectCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
+	return newItemCmd("project", "+", service.RenameProject, service.MergeProjects, presenter)
+}
+
+// NewContextCmd creates a new cobra command for renaming and merging contexts.
+func NewContextCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
+	return newItemCmd("context", "@", service.RenameContext, service.MergeContexts, presenter)
+}
+
+// newItemCmd creates a command with rename and merge subcommands for either projects or contexts
+func newItemCmd(
+	kind, prefix string,
+	rename func(from, to string) ([]todotxtlib.Todo, error),
+	merge func(from []string, to string) ([]todotxtlib.Todo, error),
+	presenter *cli.Presenter,
+) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   kind,
+		Short: fmt.Sprintf("Rename or merge %ss across your todo.txt", kind),
+		Long: fmt.Sprintf(`Renames or merges %[1]ss on every task in your todo.txt. Tasks are updated in place and keep their line
+numbers, and the number of updated tasks is printed. The %[2]s can be left out of names.
+
+# rename %[2]sold to %[2]snew
+togodo %[1]s rename %[2]sold %[2]snew
+
+# move every task in %[2]sa or %[2]sb to %[2]sc
+togodo %[1]s merge %[2]sa %[2]sb %[2]sc`, kind, prefix),
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "rename OLD NEW",
+		Short: fmt.Sprintf("Rename a %s", kind),
+		Long: fmt.Sprintf(`Renames a %[1]s on every task in it. Fails if any task is already in the new %[1]s, so that %[1]ss
+are never combined by accident; use merge for that.
+
+# rename %[2]sold to %[2]snew
+togodo %[1]s rename %[2]sold %[2]snew`, kind, prefix),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := withPrefix(args, prefix)
+
+			// Business logic - delegated to service
+			todos, err := rename(names[0], names[1])
+			if err != nil {
+				return err
+			}
+
+			// Presentation logic - handled by presenter
+			return presenter.WriteLine(fmt.Sprintf("Renamed %s to %s in %s", names[0], names[1], countTodos(len(todos))))
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "merge SOURCE... TARGET",
+		Short: fmt.Sprintf("Merge %ss into another", kind),
+		Long: fmt.Sprintf(`Moves every task in any of the SOURCE %[1]ss to the TARGET %[1]s, which may already exist.
+
+# move every task in %[2]sa or %[2]sb to %[2]sc
+togodo %[1]s merge %[2]sa %[2]sb %[2]sc`, kind, prefix),
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := withPrefix(args, prefix)
+			sources, target := names[:len(names)-1], names[len(names)-1]
+
+			// Business logic - delegated to service
+			todos, err := merge(sources, target)
+			if err != nil {
+				return err
+			}
+
+			// Presentation logic - handled by presenter
+			return presenter.WriteLine(fmt.Sprintf("Merged %s into %s in %s", strings.Join(sources, ", "), target, countTodos(len(todos))))
+		},
+	})
+
+	return cmd
+}
+
+// countTodos returns the number of todos as text, e.g. "1 todo" or "3 todos"
+func countTodos(count int) string {
+	if count == 1 {
+		return "1 todo"
+	}
+	return fmt.Sprintf("%d todos", count)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/gkarolyi/togodo/todotxtlib"
+)
+
+func TestProjectCmd_Rename(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	names := withPrefix([]string{"project1", "+project3"}, "+")
+	todos, err := service.RenameProject(names[0], names[1])
+	assertNoError(t, err)
+
+	if len(todos) != 2 {
+		t.Fatalf("Expected 2 todos, got %d", len(todos))
+	}
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1\n" +
+		"(B) test todo 2 @context2 +project3\n" +
+		"x (C) test todo 3 @context1 +project3\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestProjectCmd_RenameExisting(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	_, err := service.RenameProject("+project1", "+project2")
+	assertError(t, err)
+	assertContains(t, err.Error(), "+project2 already exists")
+}
+
+func TestProjectCmd_Merge(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	todos, err := service.MergeProjects([]string{"+project1"}, "+project2")
+	assertNoError(t, err)
+
+	if len(todos) != 2 {
+		t.Fatalf("Expected 2 todos, got %d", len(todos))
+	}
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1\n" +
+		"(B) test todo 2 @context2 +project2\n" +
+		"x (C) test todo 3 @context1 +project2\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestContextCmd_Rename(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	names := withPrefix([]string{"context1", "office"}, "@")
+	todos, err := service.RenameContext(names[0], names[1])
+	assertNoError(t, err)
+
+	if len(todos) != 2 {
+		t.Fatalf("Expected 2 todos, got %d", len(todos))
+	}
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @office\n" +
+		"(B) test todo 2 +project1 @context2\n" +
+		"x (C) test todo 3 +project1 @office\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestContextCmd_MergeInvalid(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	_, err := service.MergeContexts([]string{"@context1"}, "context2")
+	assertError(t, err)
+	assertContains(t, err.Error(), "invalid context")
+}
+
+func TestCountTodos(t *testing.T) {
+	if got := countTodos(1); got != "1 todo" {
+		t.Errorf("countTodos(1) = %q, want %q", got, "1 todo")
+	}
+	if got := countTodos(3); got != "3 todos" {
+		t.Errorf("countTodos(3) = %q, want %q", got, "3 todos")
+	}
+}
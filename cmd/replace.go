@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/todotxtlib"
+	"github.com/spf13/cobra"
+)
+
+// NewReplaceCmd creates a new cobra command for replacing a todo.
+func NewReplaceCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
+	return &cobra.Command{
+		Use:   "replace LINE_NUMBER TEXT",
+		Short: "Replace a todo item with new text",
+		Long: `Replaces the task on a line with a new task, and prints the new task. The task keeps its line number.
+
+# replace the task on line 1
+togodo replace 1 "(A) call mum @phone"
+`,
+
+		Args:        cobra.MinimumNArgs(2),
+		Annotations: map[string]string{lineArgsAnnotation: lineArgsFirst},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			index, err := lineIndex(service, args[0])
+			if err != nil {
+				return err
+			}
+
+			// Business logic - delegated to service
+			todo, err := service.ReplaceTodo(index, strings.Join(args[1:], " "))
+			if err != nil {
+				return err
+			}
+
+			// Presentation logic - handled by presenter
+			presenter.Print(todo)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/gkarolyi/togodo/todotxtlib"
+)
+
+func TestReplaceCmd(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	todo, err := service.ReplaceTodo(1, "(C) new todo +project3")
+	assertNoError(t, err)
+
+	if todo.LineNumber != 2 {
+		t.Errorf("Expected line number 2, got %d", todo.LineNumber)
+	}
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1\n" +
+		"(C) new todo +project3\n" +
+		"x (C) test todo 3 +project1 @context1\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestReplaceCmd_InvalidLineNumber(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	_, err := service.ReplaceTodo(9, "new todo")
+	assertError(t, err)
+	assertContains(t, err.Error(), "failed to replace todo")
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/todotxtlib"
+	"github.com/spf13/cobra"
+)
+
+// NewRmCmd creates a new cobra command for deleting todos.
+func NewRmCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rm [LINE NUMBER]...",
+		Short: "Delete todo items",
+		Long: `Deletes tasks from your todo.txt, and prints the deleted tasks. If any of the line numbers does not exist,
+nothing is deleted. Deleted tasks can be brought back with undo. Instead of line numbers, --where deletes every task
+matching a query as used by list. --dry-run prints the tasks that would be deleted, and deleting more tasks matching
+--where than the confirm_threshold config (10 by default) asks for confirmation unless --yes is passed.
+
+# delete the task on line 1
+togodo rm 1
+
+# delete the tasks on lines 1, 2, and 3
+togodo rm 1 2 3
+
+# delete every done task in +oldproject
+togodo rm --where '+oldproject x'
+`,
+
+		Args:        cobra.ArbitraryArgs,
+		Aliases:     []string{"del"},
+		Annotations: map[string]string{lineArgsAnnotation: lineArgsAll},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Parse line numbers (convert from 1-based to 0-based), or select todos by query
+			indices, err := selectIndices(cmd, service, args, todotxtlib.Filter{})
+			if err != nil {
+				return err
+			}
+
+			return runBatch(cmd, service, presenter, "delete", indices, service.RemoveTodos)
+		},
+	}
+
+	addBatchFlags(cmd)
+	return cmd
+}
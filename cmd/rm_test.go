@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/gkarolyi/togodo/todotxtlib"
+)
+
+func TestRmCmd_SingleTask(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	indices, err := parseLineNumbers([]string{"2"})
+	assertNoError(t, err)
+
+	todos, err := service.RemoveTodos(indices)
+	assertNoError(t, err)
+
+	if len(todos) != 1 {
+		t.Fatalf("Expected 1 todo, got %d", len(todos))
+	}
+	assertContains(t, todos[0].Text, "test todo 2")
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1\n" +
+		"x (C) test todo 3 +project1 @context1\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestRmCmd_MultipleTasks(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	indices, err := parseLineNumbers([]string{"3", "1"})
+	assertNoError(t, err)
+
+	todos, err := service.RemoveTodos(indices)
+	assertNoError(t, err)
+
+	if len(todos) != 2 {
+		t.Fatalf("Expected 2 todos, got %d", len(todos))
+	}
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(B) test todo 2 +project1 @context2\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestRmCmd_InvalidLineNumber(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	// Nothing is removed if one of the line numbers does not exist
+	indices, err := parseLineNumbers([]string{"1", "10"})
+	assertNoError(t, err)
+
+	_, err = service.RemoveTodos(indices)
+	assertError(t, err)
+	assertContains(t, err.Error(), "no todo at index 9")
+
+	todos, err := repo.ListAll()
+	assertNoError(t, err)
+	if len(todos) != 3 {
+		t.Errorf("Expected 3 todos, got %d", len(todos))
+	}
+}
+
+func TestRmCmd_Undo(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithJournal(todotxtlib.NewMemoryJournal()))
+
+	_, err := service.RemoveTodos([]int{0})
+	assertNoError(t, err)
+
+	entry, err := service.Undo()
+	assertNoError(t, err)
+	assertContains(t, entry.Operation, "remove todos")
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1\n" +
+		"(B) test todo 2 +project1 @context2\n" +
+		"x (C) test todo 3 +project1 @context1\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
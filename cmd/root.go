@@ -22,8 +22,10 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/gkarolyi/togodo/internal/cli"
 	"github.com/gkarolyi/togodo/internal/config"
@@ -33,13 +35,21 @@ import (
 )
 
 // NewRootCmd creates the root command and its subcommands, injecting dependencies.
-func NewRootCmd(service todotxtlib.TodoService, repo todotxtlib.TodoRepository, presenter *cli.Presenter) *cobra.Command {
+// The TUI uses its own service so that it can keep changes unsaved until quitting,
+// and watches todoTxtPath, the file the services' repository reads, for changes.
+func NewRootCmd(service todotxtlib.TodoService, tuiService todotxtlib.TodoService, presenter *cli.Presenter, todoTxtPath string) *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:   "togodo",
 		Short: "A CLI tool for managing your todo.txt",
 		Long:  `togodo is a CLI tool for managing your todo.txt file.`,
 		Run: func(cmd *cobra.Command, args []string) {
-			err := tui.Run(repo)
+			views, err := config.GetViews()
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			err = tui.Run(tuiService, todoTxtPath, views, queryOptions(true)...)
 			if err != nil {
 				fmt.Println(err)
 				os.Exit(1)
@@ -50,24 +60,122 @@ func NewRootCmd(service todotxtlib.TodoService, repo todotxtlib.TodoRepository,
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringP("file", "f", "", "Specify the todo.txt file to use")
 
-	// Set up persistent pre-run to handle --file flag globally
-	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+	// Set up persistent pre-run to handle --file flag globally. Commands hold the
+	// lock on todo.txt until they finish, so that other togodo processes cannot
+	// change it between reading and saving; the TUI only takes it while saving.
+	// If a command fails, the lock is released as the process exits
+	lock := &commandLock{service: service, unlock: func() {}}
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		if file, _ := cmd.Flags().GetString("file"); file != "" {
 			config.SetTodoTxtPath(file)
 		}
+		if !cmd.HasParent() {
+			return nil
+		}
+
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		cmd.SetContext(context.WithValue(ctx, commandLockKey{}, lock))
+		return lock.acquire()
+	}
+	rootCmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		lock.release()
 	}
 
 	// Add subcommands
 	rootCmd.AddCommand(NewAddCmd(service, presenter))
+	rootCmd.AddCommand(NewAppendCmd(service, presenter))
+	rootCmd.AddCommand(NewArchiveCmd(service, presenter))
+	rootCmd.AddCommand(NewContextCmd(service, presenter))
+	rootCmd.AddCommand(NewDepriCmd(service, presenter))
 	rootCmd.AddCommand(NewDoCmd(service, presenter))
+	rootCmd.AddCommand(NewDueCmd(service, presenter))
 	rootCmd.AddCommand(NewListCmd(service, presenter))
+	rootCmd.AddCommand(NewPrependCmd(service, presenter))
 	rootCmd.AddCommand(NewPriCmd(service, presenter))
+	rootCmd.AddCommand(NewProjectCmd(service, presenter))
+	rootCmd.AddCommand(NewRedoCmd(service, presenter))
+	rootCmd.AddCommand(NewReplaceCmd(service, presenter))
+	rootCmd.AddCommand(NewRmCmd(service, presenter))
+	rootCmd.AddCommand(NewTagCmd(service, presenter))
 	rootCmd.AddCommand(NewTidyCmd(service, presenter))
+	rootCmd.AddCommand(NewUndoCmd(service, presenter))
+	rootCmd.AddCommand(NewUntagCmd(service, presenter))
+	rootCmd.AddCommand(NewViewsCmd(presenter))
 	rootCmd.AddCommand(NewConfigCmd(presenter))
 
 	return rootCmd
 }
 
+// commandLockKey is the context key of the commandLock of a running command
+type commandLockKey struct{}
+
+// commandLock is the lock on todo.txt that a command holds while it runs
+type commandLock struct {
+	service todotxtlib.TodoService
+	unlock  func()
+}
+
+// acquire takes the lock, reloading the todos
+func (l *commandLock) acquire() error {
+	unlock, err := l.service.Lock()
+	if err != nil {
+		return err
+	}
+	l.unlock = unlock
+	return nil
+}
+
+// release gives up the lock, if it is held
+func (l *commandLock) release() {
+	l.unlock()
+	l.unlock = func() {}
+}
+
+// withoutLock calls f without holding the lock on todo.txt, so that other togodo
+// processes are not kept waiting meanwhile, e.g. for an answer to a question.
+// Reports whether the todos were changed by another process in the meantime
+func withoutLock(cmd *cobra.Command, service todotxtlib.TodoService, f func()) (bool, error) {
+	var lock *commandLock
+	if ctx := cmd.Context(); ctx != nil {
+		lock, _ = ctx.Value(commandLockKey{}).(*commandLock)
+	}
+	if lock == nil {
+		f()
+		return false, nil
+	}
+
+	before, err := todoTexts(service)
+	if err != nil {
+		return false, err
+	}
+	lock.release()
+	f()
+	if err := lock.acquire(); err != nil {
+		return false, err
+	}
+	after, err := todoTexts(service)
+	if err != nil {
+		return false, err
+	}
+	return !slices.Equal(before, after), nil
+}
+
+// todoTexts returns the text of every todo
+func todoTexts(service todotxtlib.TodoService) ([]string, error) {
+	todos, err := service.FilterTodos(todotxtlib.Filter{})
+	if err != nil {
+		return nil, err
+	}
+	texts := make([]string, len(todos))
+	for i, todo := range todos {
+		texts[i] = todo.Text
+	}
+	return texts, nil
+}
+
 // initConfig reads in config file and ENV variables.
 func initConfig() {
 	if err := config.InitConfig(); err != nil {
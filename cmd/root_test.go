@@ -12,7 +12,7 @@ func TestNewRootCmd(t *testing.T) {
 	service := todotxtlib.NewTodoService(repo)
 	presenter := cli.NewPresenter()
 
-	rootCmd := NewRootCmd(service, repo, presenter)
+	rootCmd := NewRootCmd(service, service, presenter, "")
 
 	if rootCmd == nil {
 		t.Fatal("NewRootCmd() returned nil")
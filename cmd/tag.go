@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/todotxtlib"
+	"github.com/spf13/cobra"
+)
+
+// splitTagArgs splits CLI arguments for the tag and untag commands into line
+// numbers and tags. The leading arguments are line numbers, unless the todos
+// are selected with --where, in which case all arguments are tags
+func splitTagArgs(args []string, where bool) ([]string, []string, error) {
+	if where {
+		return nil, args, nil
+	}
+
+	count := 0
+	for count < len(args) {
+		if !isLineArg(args[count]) {
+			break
+		}
+		count++
+	}
+	if count == len(args) {
+		return nil, nil, fmt.Errorf("expected a +project, @context or key:value tag after the line numbers")
+	}
+	return args[:count], args[count:], nil
+}
+
+// NewTagCmd creates a new cobra command for adding projects, contexts and tags to todos.
+func NewTagCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tag [LINE NUMBER]... TAG...",
+		Short: "Add projects, contexts and tags to todo items",
+		Long: `Adds +projects, @contexts and key:value tags to tasks, and prints the updated tasks. A tag replaces the value of
+an existing tag with the same key. Tasks are selected by line number, or with --where by a query as used by list.
+All tasks are changed and saved at once, and nothing is changed if any of the line numbers or tags is invalid.
+--dry-run prints the tasks that would be changed, and changing more tasks matching --where than the confirm_threshold
+config (10 by default) asks for confirmation unless --yes is passed.
+
+# add the tasks on lines 1 and 2 to +release and @review, due on 2024-12-31
+togodo tag 1 2 +release @review due:2024-12-31
+
+# add every task in +web that mentions the login page to @review
+togodo tag --where '+web login' @review
+`,
+
+		Args:        cobra.MinimumNArgs(1),
+		Annotations: map[string]string{lineArgsAnnotation: lineArgsAll},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			indices, tags, err := selectTagArgs(cmd, service, args)
+			if err != nil {
+				return err
+			}
+
+			return runBatch(cmd, service, presenter, "tag", indices, func(indices []int) ([]todotxtlib.Todo, error) {
+				return service.TagTodos(indices, tags)
+			})
+		},
+	}
+
+	addBatchFlags(cmd)
+	return cmd
+}
+
+// NewUntagCmd creates a new cobra command for removing projects, contexts and tags from todos.
+func NewUntagCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "untag [LINE NUMBER]... TAG...",
+		Short: "Remove projects, contexts and tags from todo items",
+		Long: `Removes +projects, @contexts and key:value tags from tasks, and prints the updated tasks. A tag key without a
+value, such as waiting, removes the tag whatever its value. Tasks are selected by line number, or with --where by a
+query as used by list. All tasks are changed and saved at once, and nothing is changed if any of the line numbers or
+tags is invalid. --dry-run and --yes work as for tag.
+
+# remove the tasks on lines 1 and 2 from @review
+togodo untag 1 2 @review
+
+# remove the waiting tag from every done task
+togodo untag --where x waiting
+`,
+
+		Args:        cobra.MinimumNArgs(1),
+		Annotations: map[string]string{lineArgsAnnotation: lineArgsAll},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			indices, tags, err := selectTagArgs(cmd, service, args)
+			if err != nil {
+				return err
+			}
+
+			return runBatch(cmd, service, presenter, "untag", indices, func(indices []int) ([]todotxtlib.Todo, error) {
+				return service.UntagTodos(indices, tags)
+			})
+		},
+	}
+
+	addBatchFlags(cmd)
+	return cmd
+}
+
+// selectTagArgs returns the indices (0-based) of the todos selected by the
+// arguments of the tag and untag commands, and the tags to add or remove
+func selectTagArgs(cmd *cobra.Command, service todotxtlib.TodoService, args []string) ([]int, []string, error) {
+	where, _ := cmd.Flags().GetString("where")
+	lineArgs, tags, err := splitTagArgs(args, where != "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	indices, err := selectIndices(cmd, service, lineArgs, todotxtlib.Filter{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return indices, tags, nil
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/todotxtlib"
+)
+
+// selectTagArgsForTest selects todos and tags as the tag command would with the given --where query
+func selectTagArgsForTest(service todotxtlib.TodoService, where string, args []string) ([]int, []string, error) {
+	cmd := NewTagCmd(service, cli.NewPresenter())
+	if where != "" {
+		cmd.Flags().Set("where", where)
+	}
+	return selectTagArgs(cmd, service, args)
+}
+
+func TestTagCmd_LineNumbers(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	indices, tags, err := selectTagArgsForTest(service, "", []string{"1", "2", "+release", "@review", "due:2024-12-31"})
+	assertNoError(t, err)
+
+	todos, err := service.TagTodos(indices, tags)
+	assertNoError(t, err)
+
+	if len(todos) != 2 {
+		t.Fatalf("Expected 2 todos, got %d", len(todos))
+	}
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1 +release @review due:2024-12-31\n" +
+		"(B) test todo 2 +project1 @context2 +release @review due:2024-12-31\n" +
+		"x (C) test todo 3 +project1 @context1\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestTagCmd_Where(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	indices, tags, err := selectTagArgsForTest(service, "+project1", []string{"@review"})
+	assertNoError(t, err)
+
+	if !slices.Equal(indices, []int{1, 2}) {
+		t.Fatalf("Expected indices [1 2], got %v", indices)
+	}
+
+	_, err = service.TagTodos(indices, tags)
+	assertNoError(t, err)
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1\n" +
+		"(B) test todo 2 +project1 @context2 @review\n" +
+		"x (C) test todo 3 +project1 @context1 @review\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestTagCmd_InvalidTag(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	// Nothing is changed if one of the tags is invalid
+	_, err := service.TagTodos([]int{0, 1}, []string{"@review", "not-a-tag"})
+	assertError(t, err)
+	assertContains(t, err.Error(), "invalid tag")
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+	assertContains(t, output, "(A) test todo 1 +project2 @context1\n")
+}
+
+func TestTagCmd_InvalidArgs(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	invalidArgs := [][]string{
+		{"+release"},
+		{"1", "2"},
+		{"0", "+release"},
+	}
+
+	for _, args := range invalidArgs {
+		if _, _, err := selectTagArgsForTest(service, "", args); err == nil {
+			t.Errorf("selectTagArgs(%v) expected error, got nil", args)
+		}
+	}
+
+	_, _, err := selectTagArgsForTest(service, "(", []string{"+release"})
+	assertError(t, err)
+}
+
+func TestUntagCmd(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	_, err := service.AddTodos([]string{
+		"call mum +family @phone waiting:bob",
+		"email bob +work @phone waiting:sam",
+	})
+	assertNoError(t, err)
+
+	indices, tags, err := selectTagArgsForTest(service, "", []string{"1", "2", "@phone", "+family", "waiting"})
+	assertNoError(t, err)
+
+	todos, err := service.UntagTodos(indices, tags)
+	assertNoError(t, err)
+
+	if len(todos) != 2 {
+		t.Fatalf("Expected 2 todos, got %d", len(todos))
+	}
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "call mum\n" +
+		"email bob +work\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestUntagCmd_TagValue(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	_, err := service.AddTodos([]string{"call mum waiting:bob", "email bob waiting:sam"})
+	assertNoError(t, err)
+
+	// Only tags with the given value are removed
+	_, err = service.UntagTodos([]int{0, 1}, []string{"waiting:sam"})
+	assertNoError(t, err)
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "call mum waiting:bob\n" +
+		"email bob\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gkarolyi/togodo/internal/cli"
 	"github.com/gkarolyi/togodo/todotxtlib"
@@ -16,6 +17,11 @@ var testTodos = []todotxtlib.Todo{
 	todotxtlib.NewTodo("x (C) test todo 3 +project1 @context1"),
 }
 
+// testNow is the fixed clock used by tests that depend on the current date
+func testNow() time.Time {
+	return time.Date(2024, 1, 15, 9, 30, 0, 0, time.Local)
+}
+
 // setupEmptyTestRepository creates a new Repository with an empty buffer for testing
 func setupEmptyTestRepository(tb testing.TB) (todotxtlib.TodoRepository, *bytes.Buffer) {
 	// Create an empty buffer
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/todotxtlib"
+	"github.com/spf13/cobra"
+)
+
+// NewUndoCmd creates a new cobra command for undoing the last change.
+func NewUndoCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
+	return &cobra.Command{
+		Use:   "undo [LINE NUMBER]...",
+		Short: "Undo the last change, or mark todo items as not done",
+		Long: `Undoes the last change made to your todo.txt by add, do, pri, depri, rm, replace, append, prepend, project,
+context, tag, untag, tidy or archive. Changes are recorded in a journal next to your todo.txt, so you can undo several changes in a row, and
+redo them with the redo command. Tasks changed since by other commands or programs are kept. Undoing archive puts
+the archived tasks back in your todo.txt, but leaves them in done.txt too.
+
+Given line numbers, undo instead marks those tasks as not done, removing their completion date, and prints them.
+Unlike do, tasks that are not done are left as they are.
+
+# undo the last change
+togodo undo
+
+# mark the tasks on lines 4 and 5 as not done
+togodo undo 4 5`,
+		Args:        cobra.ArbitraryArgs,
+		Annotations: map[string]string{lineArgsAnnotation: lineArgsAll},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				// Parse line numbers (convert from 1-based to 0-based), checking that every line exists
+				indices, err := lineIndices(service, args)
+				if err != nil {
+					return err
+				}
+
+				// Business logic - delegated to service
+				todos, err := service.ReopenTodos(indices)
+				if err != nil {
+					return err
+				}
+
+				// Presentation logic - handled by presenter
+				for _, todo := range todos {
+					presenter.Print(todo)
+				}
+				return nil
+			}
+
+			// Business logic - delegated to service
+			entry, err := service.Undo()
+			if err != nil {
+				return err
+			}
+
+			// Presentation logic - handled by presenter
+			return presenter.WriteLine(fmt.Sprintf("Undone: %s", entry.Operation))
+		},
+	}
+}
+
+// NewRedoCmd creates a new cobra command for redoing the last undone change.
+func NewRedoCmd(service todotxtlib.TodoService, presenter *cli.Presenter) *cobra.Command {
+	return &cobra.Command{
+		Use:   "redo",
+		Short: "Redo the last change undone with undo",
+		Long: `Redoes the last change undone with the undo command. Making another change after undoing discards the undone
+changes, so they can no longer be redone.
+
+# redo the last undone change
+togodo redo`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Business logic - delegated to service
+			entry, err := service.Redo()
+			if err != nil {
+				return err
+			}
+
+			// Presentation logic - handled by presenter
+			return presenter.WriteLine(fmt.Sprintf("Redone: %s", entry.Operation))
+		},
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gkarolyi/togodo/todotxtlib"
+)
+
+func TestUndoCmd_AfterTidy(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithJournal(todotxtlib.NewMemoryJournal()))
+
+	_, err := service.RemoveDoneTodos()
+	assertNoError(t, err)
+
+	entry, err := service.Undo()
+	assertNoError(t, err)
+	assertContains(t, entry.Operation, "remove done todos")
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := `(A) test todo 1 +project2 @context1
+(B) test todo 2 +project1 @context2
+x (C) test todo 3 +project1 @context1
+`
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestUndoCmd_AfterPri(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithJournal(todotxtlib.NewMemoryJournal()))
+
+	_, err := service.SetPriorities([]int{0, 1}, "D")
+	assertNoError(t, err)
+
+	_, err = service.Undo()
+	assertNoError(t, err)
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := `(A) test todo 1 +project2 @context1
+(B) test todo 2 +project1 @context2
+x (C) test todo 3 +project1 @context1
+`
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestRedoCmd_AfterUndo(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithJournal(todotxtlib.NewMemoryJournal()))
+
+	_, err := service.RemoveDoneTodos()
+	assertNoError(t, err)
+	_, err = service.Undo()
+	assertNoError(t, err)
+
+	entry, err := service.Redo()
+	assertNoError(t, err)
+	assertContains(t, entry.Operation, "remove done todos")
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := `(A) test todo 1 +project2 @context1
+(B) test todo 2 +project1 @context2
+`
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestUndoCmd_NothingToUndo(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo, todotxtlib.WithJournal(todotxtlib.NewMemoryJournal()))
+
+	_, err := service.Undo()
+
+	if !errors.Is(err, todotxtlib.ErrNothingToUndo) {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+}
+
+func TestUndoCmd_LineNumbers(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	// Line 1 is not done, and is left as it is
+	indices, err := parseLineNumbers([]string{"1", "3"})
+	assertNoError(t, err)
+
+	todos, err := service.ReopenTodos(indices)
+	assertNoError(t, err)
+
+	if len(todos) != 2 {
+		t.Fatalf("Expected 2 todos, got %d", len(todos))
+	}
+
+	output, err := repo.WriteToString()
+	assertNoError(t, err)
+
+	expectedOutput := "(A) test todo 1 +project2 @context1\n" +
+		"(B) test todo 2 +project1 @context2\n" +
+		"(C) test todo 3 +project1 @context1\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestUndoCmd_LineNumbersInvalid(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := todotxtlib.NewTodoService(repo)
+
+	_, err := service.ReopenTodos([]int{2, 9})
+	assertError(t, err)
+	assertContains(t, err.Error(), "failed to reopen todos")
+}
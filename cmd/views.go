@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gkarolyi/togodo/internal/cli"
+	"github.com/gkarolyi/togodo/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// NewViewsCmd creates a new cobra command for listing the views in the config file.
+func NewViewsCmd(presenter *cli.Presenter) *cobra.Command {
+	return &cobra.Command{
+		Use:   "views",
+		Short: "List the views in your config file",
+		Long: `Lists the views configured in your config file, with their query and sort. A view is a [views.NAME] table in
+~/.config/togodo/config.toml, and its tasks can be listed with list --view NAME or by pressing v in the TUI:
+
+[views.today]
+query = "due<=today -x"
+sort = "due,priority"
+
+# list the configured views
+togodo views`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			views, err := config.GetViews()
+			if err != nil {
+				return err
+			}
+
+			if len(views) == 0 {
+				return presenter.WriteLine("No views configured, add a [views.NAME] table to your config file")
+			}
+			for _, line := range formatViews(views) {
+				if err := presenter.WriteLine(line); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// formatViews returns a line for each view with its name, query and sort
+func formatViews(views []config.View) []string {
+	width := 0
+	for _, view := range views {
+		width = max(width, len(view.Name))
+	}
+
+	lines := make([]string, len(views))
+	for i, view := range views {
+		line := fmt.Sprintf("%-*s  %s", width, view.Name, view.Query)
+		if view.Sort != "" {
+			line += fmt.Sprintf(" (sort: %s)", view.Sort)
+		}
+		lines[i] = line
+	}
+	return lines
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gkarolyi/togodo/internal/config"
+	"github.com/spf13/viper"
+)
+
+func TestViews_FromConfig(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("views", map[string]any{
+		"today":   map[string]any{"query": "due<=today -x", "sort": "due,priority"},
+		"waiting": map[string]any{"query": "waiting:*"},
+	})
+
+	views, err := config.GetViews()
+	assertNoError(t, err)
+
+	output := strings.Join(formatViews(views), "\n")
+	expected := `today    due<=today -x (sort: due,priority)
+waiting  waiting:*`
+
+	if output != expected {
+		t.Errorf("Expected output:\n%s\n\nGot:\n%s", expected, output)
+	}
+
+	view, err := config.GetView("Today")
+	assertNoError(t, err)
+	if view.Name != "today" || view.Query != "due<=today -x" {
+		t.Errorf("Expected the today view, got %+v", view)
+	}
+
+	_, err = config.GetView("missing")
+	assertError(t, err)
+}
+
+func TestViews_InvalidConfig(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("views", "not a table")
+
+	_, err := config.GetViews()
+	assertError(t, err)
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/gkarolyi/togodo/todotxtlib"
@@ -22,6 +23,9 @@ type LipglossFormatter struct {
 	priorityStyle   map[string]lipgloss.Style
 	tagStyle        lipgloss.Style
 	lineNumberStyle lipgloss.Style
+	overdueStyle    lipgloss.Style
+	dueTodayStyle   lipgloss.Style
+	now             func() time.Time
 }
 
 // NewLipglossFormatter creates a new LipglossFormatter with default styles
@@ -52,6 +56,13 @@ func NewLipglossFormatter() *LipglossFormatter {
 			Foreground(lipgloss.Color("#96C5B0")),
 		lineNumberStyle: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#7F98AF")),
+		overdueStyle: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#D40B23")),
+		dueTodayStyle: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#FF6700")),
+		now: time.Now,
 	}
 }
 
@@ -61,6 +72,15 @@ func (f *LipglossFormatter) Format(todo todotxtlib.Todo) string {
 	words := strings.Fields(todo.Text)
 	stdStyle := f.priorityStyle[todo.Priority]
 
+	// Overdue todos are shown in red, and the due tag is highlighted when due today
+	dueStyle := f.tagStyle
+	if days, ok := todo.DueIn(f.now()); ok && days < 0 {
+		stdStyle = stdStyle.Foreground(f.overdueStyle.GetForeground())
+		dueStyle = f.overdueStyle
+	} else if ok && days == 0 {
+		dueStyle = f.dueTodayStyle
+	}
+
 	if todo.Done {
 		builder.WriteString(f.doneStyle.Render(todo.Text))
 	} else {
@@ -69,6 +89,8 @@ func (f *LipglossFormatter) Format(todo todotxtlib.Todo) string {
 				builder.WriteString(f.projectStyle.Render(word))
 			} else if isContext(word) {
 				builder.WriteString(f.contextStyle.Render(word))
+			} else if isDueTag(word) {
+				builder.WriteString(dueStyle.Render(word))
 			} else if isTag(word) {
 				builder.WriteString(f.tagStyle.Render(word))
 			} else {
@@ -87,8 +109,8 @@ func (f *LipglossFormatter) Format(todo todotxtlib.Todo) string {
 func (f *LipglossFormatter) FormatList(todos []todotxtlib.Todo) []string {
 	formatted := make([]string, len(todos))
 	for i, todo := range todos {
-		// Add line number (index + 1) before the formatted todo
-		lineNumber := fmt.Sprintf("%3d ", i+1)
+		// Add the todo's line number before the formatted todo
+		lineNumber := fmt.Sprintf("%3d ", listLineNumber(todo, i))
 		formatted[i] = f.lineNumberStyle.Render(lineNumber) + f.Format(todo)
 	}
 	return formatted
@@ -111,13 +133,23 @@ func (f *PlainFormatter) Format(todo todotxtlib.Todo) string {
 func (f *PlainFormatter) FormatList(todos []todotxtlib.Todo) []string {
 	formatted := make([]string, len(todos))
 	for i, todo := range todos {
-		// Add line number (index + 1) before the formatted todo
-		formatted[i] = fmt.Sprintf("%3d %s", i+1, f.Format(todo))
+		// Add the todo's line number before the formatted todo
+		formatted[i] = fmt.Sprintf("%3d %s", listLineNumber(todo, i), f.Format(todo))
 	}
 	return formatted
 }
 
 // Helper functions
+
+// listLineNumber returns the line number to show for a todo at the given index of a list,
+// falling back to its position in the list for todos that are not from a todo.txt file
+func listLineNumber(todo todotxtlib.Todo, index int) int {
+	if todo.LineNumber > 0 {
+		return todo.LineNumber
+	}
+	return index + 1
+}
+
 func isProject(word string) bool {
 	return strings.HasPrefix(word, "+")
 }
@@ -127,5 +159,11 @@ func isContext(word string) bool {
 }
 
 func isTag(word string) bool {
-	return strings.Contains(word, ":")
+	_, ok := todotxtlib.ParseTag(word)
+	return ok
+}
+
+func isDueTag(word string) bool {
+	tag, ok := todotxtlib.ParseTag(word)
+	return ok && tag.Key == "due"
 }
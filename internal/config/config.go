@@ -4,13 +4,32 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config holds the application configuration
 type Config struct {
-	TodoTxtPath string `mapstructure:"todo_txt_path"`
+	TodoTxtPath  string          `mapstructure:"todo_txt_path"`
+	KeepPriority bool            `mapstructure:"keep_priority"`
+	DateOnAdd    bool            `mapstructure:"date_on_add"`
+	DoneTxtPath  string          `mapstructure:"done_txt_path"`
+	Sort         string          `mapstructure:"sort"`
+	AutoSave     bool            `mapstructure:"autosave"`
+	LockTimeout  string          `mapstructure:"lock_timeout"`
+	SmartCase    bool            `mapstructure:"smart_case"`
+	Confirm      int             `mapstructure:"confirm_threshold"`
+	Views        map[string]View `mapstructure:"views"`
+}
+
+// View is a named search query and sort, configured in a [views.NAME] table
+type View struct {
+	Name  string `mapstructure:"-"`
+	Query string `mapstructure:"query"`
+	Sort  string `mapstructure:"sort"`
 }
 
 // InitConfig initializes Viper configuration
@@ -29,6 +48,12 @@ func InitConfig() error {
 
 	// Set default values
 	viper.SetDefault("todo_txt_path", "todo.txt")
+	viper.SetDefault("keep_priority", false)
+	viper.SetDefault("date_on_add", false)
+	viper.SetDefault("autosave", true)
+	viper.SetDefault("lock_timeout", "5s")
+	viper.SetDefault("smart_case", false)
+	viper.SetDefault("confirm_threshold", 10)
 
 	// Read config file
 	if err := viper.ReadInConfig(); err != nil {
@@ -43,9 +68,26 @@ func InitConfig() error {
 
 // GetTodoTxtPath returns the configured todo.txt file path
 func GetTodoTxtPath() string {
-	path := viper.GetString("todo_txt_path")
+	return expandHome(viper.GetString("todo_txt_path"))
+}
 
-	// Expand tilde to home directory if needed
+// GetDoneTxtPath returns the configured done.txt file path, which defaults to
+// done.txt in the same directory as todo.txt
+func GetDoneTxtPath() string {
+	if path := viper.GetString("done_txt_path"); path != "" {
+		return expandHome(path)
+	}
+	return filepath.Join(filepath.Dir(GetTodoTxtPath()), "done.txt")
+}
+
+// GetSelectionPath returns the path of the file the TUI saves the selected todos to,
+// for commands to refer to as @selected. It is kept next to todo.txt
+func GetSelectionPath() string {
+	return GetTodoTxtPath() + ".selected"
+}
+
+// expandHome expands a leading tilde to the home directory
+func expandHome(path string) string {
 	if len(path) > 1 && path[0] == '~' && path[1] == '/' {
 		homeDir, err := os.UserHomeDir()
 		if err == nil {
@@ -60,3 +102,72 @@ func GetTodoTxtPath() string {
 func SetTodoTxtPath(path string) {
 	viper.Set("todo_txt_path", path)
 }
+
+// GetKeepPriority returns whether completed todos keep their priority in a pri: tag
+func GetKeepPriority() bool {
+	return viper.GetBool("keep_priority")
+}
+
+// GetDateOnAdd returns whether new todos are stamped with a creation date
+func GetDateOnAdd() bool {
+	return viper.GetBool("date_on_add")
+}
+
+// GetSort returns the default sort specification for listing todos, e.g. "due,priority"
+func GetSort() string {
+	return viper.GetString("sort")
+}
+
+// GetAutoSave returns whether changes made in the TUI are saved as soon as they are made
+func GetAutoSave() bool {
+	return viper.GetBool("autosave")
+}
+
+// GetSmartCase returns whether searches containing upper case letters match case exactly
+func GetSmartCase() bool {
+	return viper.GetBool("smart_case")
+}
+
+// GetConfirmThreshold returns how many todos a command may change at once with --where
+// before asking for confirmation, or zero to never ask
+func GetConfirmThreshold() int {
+	return viper.GetInt("confirm_threshold")
+}
+
+// GetLockTimeout returns how long to wait for another togodo to release the lock on todo.txt
+func GetLockTimeout() time.Duration {
+	return viper.GetDuration("lock_timeout")
+}
+
+// GetViews returns the configured views sorted by name. Names are lower case,
+// as config keys are case-insensitive
+func GetViews() ([]View, error) {
+	var configured map[string]View
+	if err := viper.UnmarshalKey("views", &configured); err != nil {
+		return nil, fmt.Errorf("invalid views in config file: %w", err)
+	}
+
+	views := make([]View, 0, len(configured))
+	for name, view := range configured {
+		view.Name = name
+		views = append(views, view)
+	}
+	slices.SortFunc(views, func(a, b View) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return views, nil
+}
+
+// GetView returns the view with the given name, ignoring case
+func GetView(name string) (View, error) {
+	views, err := GetViews()
+	if err != nil {
+		return View{}, err
+	}
+	for _, view := range views {
+		if strings.EqualFold(view.Name, name) {
+			return view, nil
+		}
+	}
+	return View{}, fmt.Errorf("no view named %q in config file", name)
+}
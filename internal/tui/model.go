@@ -1,9 +1,15 @@
 package tui
 
 import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/gkarolyi/togodo/internal/config"
 	"github.com/gkarolyi/togodo/todotxtlib"
 )
 
@@ -14,49 +20,206 @@ var (
 )
 
 type model struct {
-	choices    []todotxtlib.Todo // items on the to-do list
-	cursor     int               // which to-do list item our cursor is pointing at
-	selected   map[int]struct{}  // which to-do items are selected
-	repository todotxtlib.TodoRepository
-	filtering  bool            // whether we're currently filtering
-	filter     string          // the current filter string
-	adding     bool            // whether we're currently adding a new item
-	input      textinput.Model // text input for new items
-	setting    bool            // whether we're currently setting priority
-}
-
-func initialModel(repository todotxtlib.TodoRepository) model {
+	choices   []todotxtlib.Todo // items on the to-do list
+	cursor    int               // which to-do list item our cursor is pointing at
+	selected  map[int]struct{}  // line numbers of the selected to-do items
+	service   todotxtlib.TodoService
+	filtering bool                     // whether we're currently filtering
+	filter    string                   // the current filter string
+	query     todotxtlib.Query         // the last filter string that was a valid query
+	queryOpts []todotxtlib.QueryOption // options for parsing the filter string
+	filterErr error                    // why the current filter string is not a valid query, if it is not
+	views     []config.View            // views from the config file, switched between with v
+	view      int                      // index of the active view in views, -1 if none is active
+	viewQuery todotxtlib.Query         // query of the active view
+	viewSort  *todotxtlib.Sort         // sort of the active view, if it has one
+	adding    bool                     // whether we're currently adding a new item
+	input     textinput.Model          // text input for new items
+	setting   bool                     // whether we're currently setting priority
+	showAll   bool                     // whether to show items with a future threshold date
+	quitting  bool                     // whether we're asking to confirm quitting with unsaved changes
+	err       error                    // the last error, shown until the next successful action
+}
+
+func initialModel(service todotxtlib.TodoService, views []config.View, queryOptions ...todotxtlib.QueryOption) model {
 	ti := textinput.New()
 	ti.Placeholder = "Enter new todo item..."
 	ti.CharLimit = 150
 	ti.Width = 50
 
-	allTodos, err := repository.ListAll()
+	m := model{
+		service:   service,
+		selected:  make(map[int]struct{}),
+		filtering: false,
+		filter:    "",
+		adding:    false,
+		setting:   false,
+		showAll:   false,
+		input:     ti,
+		queryOpts: queryOptions,
+		views:     views,
+		view:      -1,
+	}
+	m.refresh()
+	return m
+}
+
+// refresh reloads the visible items from the service, applying the active view
+// and the current filter and hiding items with a future threshold date unless
+// showAll is set. While filtering, the best matches are shown first, otherwise
+// the items are sorted as the view says. While the filter is not a valid query,
+// such as halfway through typing it, the last valid one stays applied
+func (m *model) refresh() {
+	query, err := todotxtlib.ParseQuery(m.filter, m.queryOpts...)
+	m.filterErr = err
+	if err == nil {
+		m.query = query
+	}
+
+	query = m.query
+	if m.viewQuery != nil {
+		query = todotxtlib.AllOf(m.viewQuery, m.query)
+	}
+
+	searching := strings.TrimSpace(m.filter) != ""
+	todos, err := m.service.FilterTodos(todotxtlib.Filter{Query: query, HideFuture: !m.showAll, Rank: searching})
 	if err != nil {
-		return model{
-			repository: repository,
-			choices:    []todotxtlib.Todo{},
-			selected:   make(map[int]struct{}),
-			filtering:  false,
-			filter:     "",
-			adding:     false,
-			setting:    false,
-			input:      ti,
+		m.err = err
+		todos = []todotxtlib.Todo{}
+	}
+	if m.viewSort != nil && !searching {
+		m.viewSort.Apply(todos)
+	}
+	m.choices = todos
+
+	if m.cursor >= len(m.choices) {
+		m.cursor = len(m.choices) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// nextView switches to the next view, or back to showing all items after the
+// last one. Views with an invalid query or sort are skipped and shown as errors
+func (m *model) nextView() {
+	m.viewQuery, m.viewSort = nil, nil
+	for m.view++; m.view < len(m.views); m.view++ {
+		view := m.views[m.view]
+		query, err := todotxtlib.ParseQuery(view.Query, m.queryOpts...)
+		if err != nil {
+			m.err = fmt.Errorf("invalid query in view %q: %w", view.Name, err)
+			continue
+		}
+		if view.Sort != "" {
+			sort, err := todotxtlib.ParseSort(view.Sort)
+			if err != nil {
+				m.err = fmt.Errorf("invalid sort in view %q: %w", view.Name, err)
+				continue
+			}
+			m.viewSort = &sort
+		}
+		m.viewQuery = query
+		return
+	}
+	m.view = -1
+}
+
+// reload picks up changes made to todo.txt by other programs, keeping the cursor
+// and selection on the same items. Conflicts with unsaved changes are shown as errors
+func (m *model) reload() {
+	var current string
+	if m.cursor < len(m.choices) {
+		current = m.choices[m.cursor].Text
+	}
+
+	selectedTexts := m.selectedTexts()
+	if err := m.service.Reload(); err != nil {
+		m.err = err
+		return
+	}
+	m.refresh()
+
+	if index := slices.IndexFunc(m.choices, func(todo todotxtlib.Todo) bool { return todo.Text == current }); index >= 0 {
+		m.cursor = index
+	}
+	m.reselect(selectedTexts)
+}
+
+// selectedTexts returns the texts of the selected items, which unlike their line
+// numbers still identify them after the list has been sorted
+func (m model) selectedTexts() []string {
+	texts := []string{}
+	if all, err := m.service.FilterTodos(todotxtlib.Filter{}); err == nil {
+		for _, todo := range all {
+			if _, ok := m.selected[todo.LineNumber]; ok {
+				texts = append(texts, todo.Text)
+			}
 		}
-	} else {
-		return model{
-			repository: repository,
-			choices:    allTodos,
-			selected:   make(map[int]struct{}),
-			filtering:  false,
-			filter:     "",
-			adding:     false,
-			setting:    false,
-			input:      ti,
+	}
+	return texts
+}
+
+// reselect selects the items with the given texts, see selectedTexts
+func (m *model) reselect(texts []string) {
+	m.selected = make(map[int]struct{})
+	if all, err := m.service.FilterTodos(todotxtlib.Filter{}); err == nil {
+		for _, todo := range all {
+			if index := slices.Index(texts, todo.Text); index >= 0 {
+				m.selected[todo.LineNumber] = struct{}{}
+				texts = slices.Delete(texts, index, index+1)
+			}
 		}
 	}
 }
 
+// selectedIndices returns the 0-based indices of the selected items, in order
+func (m model) selectedIndices() []int {
+	indices := make([]int, 0, len(m.selected))
+	for lineNumber := range m.selected {
+		indices = append(indices, lineNumber-1)
+	}
+	slices.Sort(indices)
+	return indices
+}
+
+// exportSelection saves the texts of the selected items, or of the item under the
+// cursor if none are selected, for the CLI to refer to them as @selected
+func (m model) exportSelection() error {
+	all, err := m.service.FilterTodos(todotxtlib.Filter{})
+	if err != nil {
+		return err
+	}
+
+	texts := []string{}
+	for _, index := range m.selectedIndices() {
+		if index < len(all) {
+			texts = append(texts, all[index].Text)
+		}
+	}
+	if len(texts) == 0 && len(m.choices) > 0 {
+		texts = append(texts, m.choices[m.cursor].Text)
+	}
+	if len(texts) == 0 {
+		return fmt.Errorf("no todos to export")
+	}
+
+	// The selection holds the text of todos, so it gets the permissions of todo.txt
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(config.GetTodoTxtPath()); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	content := strings.Join(texts, "\n") + "\n"
+	if err := os.WriteFile(config.GetSelectionPath(), []byte(content), mode); err != nil {
+		return fmt.Errorf("failed to export selection: %w", err)
+	}
+	if err := os.Chmod(config.GetSelectionPath(), mode); err != nil {
+		return fmt.Errorf("failed to export selection: %w", err)
+	}
+	return nil
+}
+
 func (m model) Init() tea.Cmd {
 	// Just return `nil`, which means "no I/O right now, please."
 	return nil
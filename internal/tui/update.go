@@ -1,24 +1,63 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gkarolyi/togodo/internal/config"
 	"github.com/gkarolyi/togodo/todotxtlib"
 )
 
-// Run starts the TUI interface
-func Run(repo todotxtlib.TodoRepository) error {
-	model := initialModel(repo)
+// Run starts the TUI interface, making all changes through the given service and
+// reloading the todos whenever the todo.txt file at path is changed by another program.
+// The / filter is parsed with the given query options and shows the best matches first,
+// and v switches between the given views
+func Run(service todotxtlib.TodoService, path string, views []config.View, queryOptions ...todotxtlib.QueryOption) error {
+	model := initialModel(service, views, queryOptions...)
+
+	watcher, err := newFileWatcher(path)
+	if err != nil {
+		model.err = fmt.Errorf("not watching todo.txt for changes: %w", err)
+	}
+
 	p := tea.NewProgram(model)
-	_, err := p.Run()
+	if watcher != nil {
+		defer watcher.Close()
+		go watcher.run(p.Send)
+	}
+
+	_, err = p.Run()
 	return err
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case fileChangedMsg:
+		m.reload()
+		return m, nil
+
 	case tea.KeyMsg:
+		// If we're confirming quitting with unsaved changes, handle the confirmation keys
+		if m.quitting {
+			switch msg.String() {
+			case "y", "Y":
+				if err := m.service.Save(); err != nil {
+					m.err = err
+					m.quitting = false
+					return m, nil
+				}
+				return m, tea.Quit
+			case "n", "N", "ctrl+c":
+				return m, tea.Quit
+			case "esc":
+				m.quitting = false
+				return m, nil
+			}
+			return m, nil
+		}
+
 		// If we're setting priority, handle priority keys
 		if m.setting {
 			switch msg.String() {
@@ -27,11 +66,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			case "a", "b", "c", "d", "A", "B", "C", "D":
 				priority := strings.ToUpper(msg.String())
-				for i := range m.selected {
-					m.repository.SetPriority(i, priority)
-				}
-				allTodos, _ := m.repository.ListAll()
-				m.choices = allTodos
+				_, m.err = m.service.SetPriorities(m.selectedIndices(), priority)
+				m.refresh()
 				m.setting = false
 				return m, nil
 			}
@@ -48,9 +84,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			case tea.KeyEnter:
 				if m.input.Value() != "" {
-					m.repository.Add(m.input.Value())
-					allTodos, _ := m.repository.ListAll()
-					m.choices = allTodos
+					// Adding sorts the list, so the selection is kept on the same items by their text
+					selectedTexts := m.selectedTexts()
+					_, m.err = m.service.AddTodos([]string{m.input.Value()})
+					m.reselect(selectedTexts)
+					m.refresh()
 					m.adding = false
 					m.input.Reset()
 					m.input.Blur()
@@ -69,8 +107,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case tea.KeyEsc:
 				m.filtering = false
 				m.filter = ""
-				allTodos, _ := m.repository.ListAll()
-				m.choices = allTodos
+				m.refresh()
 				return m, nil
 			case tea.KeyEnter:
 				m.filtering = false
@@ -78,26 +115,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case tea.KeyBackspace:
 				if len(m.filter) > 0 {
 					m.filter = m.filter[:len(m.filter)-1]
-					filteredTodos, _ := m.repository.Search(m.filter)
-					m.choices = filteredTodos
-					if m.cursor >= len(m.choices) {
-						m.cursor = len(m.choices) - 1
-					}
-					if m.cursor < 0 {
-						m.cursor = 0
-					}
+					m.refresh()
 				}
 				return m, nil
 			default:
 				m.filter += msg.String()
-				filteredTodos, _ := m.repository.Search(m.filter)
-				m.choices = filteredTodos
-				if m.cursor >= len(m.choices) {
-					m.cursor = len(m.choices) - 1
-				}
-				if m.cursor < 0 {
-					m.cursor = 0
-				}
+				m.refresh()
 				return m, nil
 			}
 		}
@@ -105,8 +128,26 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Regular key handling when not adding or filtering
 		switch msg.String() {
 		case "ctrl+c", "q":
+			if m.service.Dirty() {
+				m.quitting = true
+				return m, nil
+			}
 			return m, tea.Quit
 
+		case "w", "ctrl+s":
+			m.err = m.service.Save()
+
+		case "u":
+			// Undoing changes the list, so line numbers of the selection no longer apply
+			_, m.err = m.service.Undo()
+			m.selected = make(map[int]struct{})
+			m.refresh()
+
+		case "ctrl+r":
+			_, m.err = m.service.Redo()
+			m.selected = make(map[int]struct{})
+			m.refresh()
+
 		case "up", "k":
 			if m.cursor > 0 {
 				m.cursor--
@@ -118,19 +159,36 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case " ":
-			_, ok := m.selected[m.cursor]
+			if len(m.choices) == 0 {
+				break
+			}
+			lineNumber := m.choices[m.cursor].LineNumber
+			_, ok := m.selected[lineNumber]
 			if ok {
-				delete(m.selected, m.cursor)
+				delete(m.selected, lineNumber)
 			} else {
-				m.selected[m.cursor] = struct{}{}
+				m.selected[lineNumber] = struct{}{}
 			}
 
 		case "x":
-			for i := range m.selected {
-				m.repository.ToggleDone(i)
+			// Toggling sorts the list, so line numbers of the selection no longer apply
+			_, m.err = m.service.ToggleTodos(m.selectedIndices())
+			m.selected = make(map[int]struct{})
+			m.refresh()
+
+		case "e":
+			m.err = m.exportSelection()
+
+		case "t":
+			m.showAll = !m.showAll
+			m.refresh()
+
+		case "v":
+			if len(m.views) > 0 {
+				m.nextView()
+				m.cursor = 0
+				m.refresh()
 			}
-			allTodos, _ := m.repository.ListAll()
-			m.choices = allTodos
 
 		case "/":
 			if !m.adding {
@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/gkarolyi/togodo/todotxtlib"
@@ -17,13 +18,24 @@ var (
 	priorityAStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#D40B23"))
 	priorityBStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FF6700"))
 	priorityCStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#0FFF95"))
+	overdueStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#D40B23"))
+	dueTodayStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FF6700"))
 )
 
 func (m model) View() string {
 	// First build the main view
 	var mainView string
+	if m.service.Dirty() {
+		mainView += "\n" + styleHelp.Render("[modified]")
+	}
+	if m.view >= 0 {
+		mainView += fmt.Sprintf("\nView: %s", m.views[m.view].Name)
+	}
 	if m.filtering {
 		mainView += fmt.Sprintf("\nFilter: %s", m.filter)
+		if m.filterErr != nil {
+			mainView += " " + styleHelp.Render(m.filterErr.Error())
+		}
 	}
 	mainView += "\n\n"
 
@@ -33,11 +45,43 @@ func (m model) View() string {
 		if m.cursor == i {
 			cursor = ">"
 		}
-		mainView += fmt.Sprintf("%s ", cursor)
+		mainView += fmt.Sprintf("%s %3d ", cursor, choice.LineNumber)
 		mainView += formatTodo(choice) + "\n"
 	}
 
-	mainView += "\nx: toggle | p: set priority | /: filter | a: add | q: quit\n"
+	mainView += "\nx: toggle | p: set priority | /: filter | a: add | e: export selection | t: show/hide future | u: undo | ctrl+r: redo | w: save | q: quit"
+	if len(m.views) > 0 {
+		mainView += " | v: switch view"
+	}
+	mainView += "\n"
+	if m.err != nil {
+		mainView += fmt.Sprintf("\nError: %v\n", m.err)
+	}
+
+	// If we're confirming quitting with unsaved changes, show the confirmation overlay
+	if m.quitting {
+		width := 40
+		height := 3
+
+		popup := stylePrimaryBold.Render("Unsaved Changes") + "\n"
+		popup += "Save before quitting? (y/n)" + "\n"
+		popup += styleHelp.Render("(esc to cancel)")
+
+		overlay := stylePrimary.
+			Width(width).
+			Height(height).
+			Align(lipgloss.Center).
+			Border(lipgloss.RoundedBorder()).
+			Render(popup)
+
+		return lipgloss.Place(
+			lipgloss.Width(mainView),
+			lipgloss.Height(mainView),
+			lipgloss.Center,
+			lipgloss.Center,
+			overlay,
+		)
+	}
 
 	// If we're setting priority, show the priority overlay
 	if m.setting {
@@ -72,16 +116,7 @@ func (m model) View() string {
 
 		popup := stylePrimaryBold.Render("Add New Todo") + "\n"
 
-		// Create a Todo with proper priority parsing
-		text := m.input.Value()
-		priority := ""
-		if len(text) >= 3 && text[0] == '(' && text[2] == ')' {
-			priority = string(text[1])
-		}
-		popup += formatTodo(todotxtlib.Todo{
-			Text:     text,
-			Priority: priority,
-		}) + "\n"
+		popup += formatTodo(todotxtlib.NewTodo(m.input.Value())) + "\n"
 		popup += styleHelp.Render("(esc to cancel, enter to save)")
 
 		overlay := stylePrimary.
@@ -121,15 +156,27 @@ func formatTodo(todo todotxtlib.Todo) string {
 		stdStyle = lipgloss.NewStyle() // Default unstyled
 	}
 
+	// Overdue todos are shown in red, and the due tag is highlighted when due today
+	dueStyle := tagStyle
+	if days, ok := todo.DueIn(time.Now()); ok && days < 0 {
+		stdStyle = stdStyle.Foreground(overdueStyle.GetForeground())
+		dueStyle = overdueStyle
+	} else if ok && days == 0 {
+		dueStyle = dueTodayStyle
+	}
+
 	if todo.Done {
 		builder.WriteString(doneStyle.Render(todo.Text))
 	} else {
 		for i, word := range words {
+			tag, isTag := todotxtlib.ParseTag(word)
 			if strings.HasPrefix(word, "+") {
 				builder.WriteString(projectStyle.Render(word))
 			} else if strings.HasPrefix(word, "@") {
 				builder.WriteString(contextStyle.Render(word))
-			} else if strings.Contains(word, ":") {
+			} else if isTag && tag.Key == "due" {
+				builder.WriteString(dueStyle.Render(word))
+			} else if isTag {
 				builder.WriteString(tagStyle.Render(word))
 			} else {
 				builder.WriteString(stdStyle.Render(word))
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+)
+
+// fileChangedMsg is sent when the todo.txt file changes on disk
+type fileChangedMsg struct{}
+
+// fileWatcher watches the todo.txt file for changes made by other programs
+type fileWatcher struct {
+	watcher *fsnotify.Watcher
+	path    string
+}
+
+// newFileWatcher starts watching the file at path. The directory is watched rather
+// than the file itself, as saving replaces the file by renaming a new one over it
+func newFileWatcher(path string) (*fileWatcher, error) {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	return &fileWatcher{watcher: watcher, path: path}, nil
+}
+
+// run sends a fileChangedMsg for every change to the file until the watcher is closed
+func (w *fileWatcher) run(send func(tea.Msg)) {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Name == w.path && event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) {
+				send(fileChangedMsg{})
+			}
+		case _, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// Close stops watching the file
+func (w *fileWatcher) Close() error {
+	return w.watcher.Close()
+}
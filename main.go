@@ -45,17 +45,27 @@ func main() {
 	reader := todotxtlib.NewFileReader(todoTxtPath)
 	writer := todotxtlib.NewFileWriter(todoTxtPath)
 
-	repo, err := todotxtlib.NewFileRepository(reader, writer)
+	repo, err := todotxtlib.NewFileRepository(reader, writer,
+		todotxtlib.WithLocking(todoTxtPath, config.GetLockTimeout()))
 	if err != nil {
 		log.Fatalf("Failed to create repository: %v", err)
 	}
 
 	// Create service layer
-	service := todotxtlib.NewTodoService(repo)
+	serviceOptions := []todotxtlib.ServiceOption{
+		todotxtlib.WithPriorityTag(config.GetKeepPriority()),
+		todotxtlib.WithDateOnAdd(config.GetDateOnAdd()),
+		todotxtlib.WithArchive(todotxtlib.NewAppendFileWriter(config.GetDoneTxtPath())),
+		todotxtlib.WithJournal(todotxtlib.NewFileJournal(todoTxtPath)),
+	}
+	service := todotxtlib.NewTodoService(repo, serviceOptions...)
+	tuiService := todotxtlib.NewTodoService(repo,
+		append(serviceOptions, todotxtlib.WithAutoSave(config.GetAutoSave()))...)
 
 	presenter := cli.NewPresenter()
 
-	rootCmd := cmd.NewRootCmd(service, repo, presenter)
+	rootCmd := cmd.NewRootCmd(service, tuiService, presenter, todoTxtPath)
+	rootCmd.SetArgs(cmd.RewriteLineArgs(rootCmd, os.Args[1:]))
 
 	if err := fang.Execute(context.Background(), rootCmd); err != nil {
 		os.Exit(1)
@@ -0,0 +1,26 @@
+package todotxtlib
+
+import "time"
+
+// DateLayout is the layout of dates in todo.txt files, e.g. 2024-01-31.
+const DateLayout = "2006-01-02"
+
+// parseDate parses a todo.txt date in local time, returning the zero time if
+// the value is empty or invalid.
+func parseDate(value string) time.Time {
+	date, err := time.ParseInLocation(DateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
+
+// daysBetween returns the number of calendar days from one date to another,
+// ignoring the time of day and daylight saving changes
+func daysBetween(from, to time.Time) int {
+	fromYear, fromMonth, fromDay := from.In(time.Local).Date()
+	toYear, toMonth, toDay := to.In(time.Local).Date()
+	fromUTC := time.Date(fromYear, fromMonth, fromDay, 0, 0, 0, 0, time.UTC)
+	toUTC := time.Date(toYear, toMonth, toDay, 0, 0, 0, 0, time.UTC)
+	return int(toUTC.Sub(fromUTC).Hours() / 24)
+}
@@ -1,59 +1,224 @@
 package todotxtlib
 
 import (
+	"cmp"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Filter holds criteria for filtering todos
 type Filter struct {
-	Done     string // "true", "false", or "" (empty string means don't filter by done status)
-	Priority string
-	Project  string
-	Context  string
-	Text     string
+	Done       string // "true", "false", or "" (empty string means don't filter by done status)
+	Priorities Set    // priority letters, e.g. Set{Any: []string{"A", "B"}}
+	Projects   Set    // projects including the +, e.g. Set{All: []string{"+release"}}
+	Contexts   Set    // contexts including the @, e.g. Set{None: []string{"@home"}}
+	Tags       Set    // key:value tags, or keys to match a tag with any value
+	Text       string
+	Query      Query // parsed search query, see ParseQuery
+
+	// Matching options. Text, priorities, projects, contexts and tags are
+	// matched ignoring case unless Case says otherwise, and Text is matched
+	// fuzzily if Fuzzy is set
+	Case  caseMode
+	Fuzzy bool
+
+	// Rank orders the matching todos by how well they match Text and the text
+	// terms of Query, best first, rather than keeping their order
+	Rank bool
+
+	// HideFuture excludes todos whose t: threshold date is after Today
+	HideFuture bool
+
+	// Due date criteria. A todo matches if it meets any of the criteria that are set.
+	Overdue   bool      // due before today
+	DueToday  bool      // due today
+	DueWithin int       // due between today and the given number of days from today
+	Today     time.Time // reference date for the date criteria, zero means the current date
+}
+
+// Set holds values that a todo's priority, projects, contexts or tags are
+// matched against. Empty lists are ignored, so the zero Set matches every todo
+type Set struct {
+	Any  []string // the todo has at least one of these
+	All  []string // the todo has all of these
+	None []string // the todo has none of these
+}
+
+// IsEmpty reports whether the set has no values to match
+func (s Set) IsEmpty() bool {
+	return len(s.Any) == 0 && len(s.All) == 0 && len(s.None) == 0
+}
+
+// matches checks if a todo meets the set, given a function reporting whether it has a value
+func (s Set) matches(has func(value string) bool) bool {
+	if len(s.Any) > 0 && !slices.ContainsFunc(s.Any, has) {
+		return false
+	}
+	for _, value := range s.All {
+		if !has(value) {
+			return false
+		}
+	}
+	return !slices.ContainsFunc(s.None, has)
 }
 
 // Apply applies the filter criteria to a list of todos and returns the matching ones
 func (f Filter) Apply(todos []Todo) []Todo {
 	var filtered []Todo
+	var scores []int
 
 	for _, todo := range todos {
-		if f.matches(todo) {
+		if score, ok := f.score(todo); ok {
 			filtered = append(filtered, todo)
+			scores = append(scores, score)
 		}
 	}
 
+	if f.Rank {
+		rankByScore(filtered, scores)
+	}
+
 	return filtered
 }
 
-// matches checks if a todo matches all the filter criteria
-func (f Filter) matches(todo Todo) bool {
+// rankByScore orders the todos by their scores, highest first, keeping the
+// order of todos with the same score
+func rankByScore(todos []Todo, scores []int) {
+	order := make([]int, len(todos))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(scores[b], scores[a])
+	})
+
+	ranked := make([]Todo, len(todos))
+	for i, index := range order {
+		ranked[i] = todos[index]
+	}
+	copy(todos, ranked)
+}
+
+// Matches checks if a todo matches all the filter criteria
+func (f Filter) Matches(todo Todo) bool {
+	_, ok := f.score(todo)
+	return ok
+}
+
+// score checks if a todo matches all the filter criteria, and returns how well
+// it matches the search text and query
+func (f Filter) score(todo Todo) (int, bool) {
+	score := 0
 	// Check done status
 	if f.Done != "" && strconv.FormatBool(todo.Done) != f.Done {
-		return false
+		return 0, false
 	}
 
-	// Check priority
-	if f.Priority != "" && todo.Priority != f.Priority {
-		return false
+	// Check priorities
+	if !f.Priorities.matches(func(priority string) bool {
+		return todo.Priority != "" && f.Case.equal(todo.Priority, priority)
+	}) {
+		return 0, false
 	}
 
-	// Check project
-	if f.Project != "" && !slices.Contains(todo.Projects, f.Project) {
-		return false
+	// Check projects
+	if !f.Projects.matches(f.hasAny(todo.Projects)) {
+		return 0, false
 	}
 
-	// Check context
-	if f.Context != "" && !slices.Contains(todo.Contexts, f.Context) {
-		return false
+	// Check contexts
+	if !f.Contexts.matches(f.hasAny(todo.Contexts)) {
+		return 0, false
+	}
+
+	// Check tags
+	if !f.Tags.matches(f.hasTag(todo)) {
+		return 0, false
 	}
 
 	// Check text content
-	if f.Text != "" && !strings.Contains(todo.Text, f.Text) {
+	if f.Text != "" {
+		textScore, ok := newTextMatcher(f.Text, f.Case, f.Fuzzy).score(todo.Text)
+		if !ok {
+			return 0, false
+		}
+		score += textScore
+	}
+
+	// Check search query
+	if f.Query != nil {
+		queryScore, ok := scoreQuery(f.Query, todo, f.today())
+		if !ok {
+			return 0, false
+		}
+		score += queryScore
+	}
+
+	// Check threshold date
+	if f.HideFuture && !todo.Actionable(f.today()) {
+		return 0, false
+	}
+
+	// Check due date
+	if f.hasDueCriteria() && !f.matchesDue(todo) {
+		return 0, false
+	}
+
+	return score, true
+}
+
+// hasAny returns a function reporting whether values contains a value
+func (f Filter) hasAny(values []string) func(string) bool {
+	return func(value string) bool {
+		return slices.ContainsFunc(values, func(v string) bool {
+			return f.Case.equal(v, value)
+		})
+	}
+}
+
+// hasTag returns a function reporting whether the todo has a key:value tag,
+// or a tag with the given key
+func (f Filter) hasTag(todo Todo) func(string) bool {
+	return func(value string) bool {
+		key, want, hasValue := strings.Cut(value, ":")
+		for _, tag := range todo.Tags {
+			if tag.Key == key && (!hasValue || f.Case.equal(tag.Value, want)) {
+				return true
+			}
+		}
 		return false
 	}
+}
+
+// today returns the reference date for the date criteria
+func (f Filter) today() time.Time {
+	if f.Today.IsZero() {
+		return time.Now()
+	}
+	return f.Today
+}
 
-	return true
+// hasDueCriteria reports whether any due date criteria are set
+func (f Filter) hasDueCriteria() bool {
+	return f.Overdue || f.DueToday || f.DueWithin > 0
+}
+
+// matchesDue checks if a todo meets any of the due date criteria
+func (f Filter) matchesDue(todo Todo) bool {
+	days, ok := todo.DueIn(f.today())
+	if !ok {
+		return false
+	}
+
+	switch {
+	case f.Overdue && days < 0:
+		return true
+	case f.DueToday && days == 0:
+		return true
+	case f.DueWithin > 0 && days >= 0 && days <= f.DueWithin:
+		return true
+	}
+	return false
 }
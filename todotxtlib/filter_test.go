@@ -4,6 +4,7 @@ import (
 	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestFilter_Apply(t *testing.T) {
@@ -28,7 +29,7 @@ func TestFilter_Apply(t *testing.T) {
 	})
 
 	t.Run("filter by priority", func(t *testing.T) {
-		filter := Filter{Priority: "A"}
+		filter := Filter{Priorities: Set{Any: []string{"A"}}}
 		filtered := filter.Apply(todos)
 
 		if len(filtered) != 1 {
@@ -40,7 +41,7 @@ func TestFilter_Apply(t *testing.T) {
 	})
 
 	t.Run("filter by project", func(t *testing.T) {
-		filter := Filter{Project: "+project1"}
+		filter := Filter{Projects: Set{All: []string{"+project1"}}}
 		filtered := filter.Apply(todos)
 
 		if len(filtered) != 2 {
@@ -54,7 +55,7 @@ func TestFilter_Apply(t *testing.T) {
 	})
 
 	t.Run("filter by context", func(t *testing.T) {
-		filter := Filter{Context: "@context1"}
+		filter := Filter{Contexts: Set{All: []string{"@context1"}}}
 		filtered := filter.Apply(todos)
 
 		if len(filtered) != 2 {
@@ -81,9 +82,9 @@ func TestFilter_Apply(t *testing.T) {
 
 	t.Run("filter by multiple criteria", func(t *testing.T) {
 		filter := Filter{
-			Done:     "false",
-			Priority: "A",
-			Project:  "+project2",
+			Done:       "false",
+			Priorities: Set{Any: []string{"A"}},
+			Projects:   Set{All: []string{"+project2"}},
 		}
 		filtered := filter.Apply(todos)
 
@@ -117,71 +118,237 @@ func TestFilter_matches(t *testing.T) {
 
 	t.Run("matches done status", func(t *testing.T) {
 		filter := Filter{Done: "false"}
-		if !filter.matches(todo) {
-			t.Error("Filter.matches() should match todo with matching done status")
+		if !filter.Matches(todo) {
+			t.Error("Filter.Matches() should match todo with matching done status")
 		}
 	})
 
 	t.Run("matches priority", func(t *testing.T) {
-		filter := Filter{Priority: "A"}
-		if !filter.matches(todo) {
-			t.Error("Filter.matches() should match todo with matching priority")
+		filter := Filter{Priorities: Set{Any: []string{"A"}}}
+		if !filter.Matches(todo) {
+			t.Error("Filter.Matches() should match todo with matching priority")
 		}
 	})
 
 	t.Run("matches project", func(t *testing.T) {
-		filter := Filter{Project: "+project1"}
-		if !filter.matches(todo) {
-			t.Error("Filter.matches() should match todo with matching project")
+		filter := Filter{Projects: Set{All: []string{"+project1"}}}
+		if !filter.Matches(todo) {
+			t.Error("Filter.Matches() should match todo with matching project")
 		}
 	})
 
 	t.Run("matches context", func(t *testing.T) {
-		filter := Filter{Context: "@context1"}
-		if !filter.matches(todo) {
-			t.Error("Filter.matches() should match todo with matching context")
+		filter := Filter{Contexts: Set{All: []string{"@context1"}}}
+		if !filter.Matches(todo) {
+			t.Error("Filter.Matches() should match todo with matching context")
 		}
 	})
 
 	t.Run("matches text content", func(t *testing.T) {
 		filter := Filter{Text: "test"}
-		if !filter.matches(todo) {
-			t.Error("Filter.matches() should match todo with matching text content")
+		if !filter.Matches(todo) {
+			t.Error("Filter.Matches() should match todo with matching text content")
+		}
+	})
+
+	t.Run("matches text content ignoring case", func(t *testing.T) {
+		filter := Filter{Text: "TEST"}
+		if !filter.Matches(todo) {
+			t.Error("Filter.Matches() should match todo with text content in a different case")
+		}
+	})
+
+	t.Run("does not match text content in a different case with smart case", func(t *testing.T) {
+		filter := Filter{Text: "TEST", Case: SmartCase}
+		if filter.Matches(todo) {
+			t.Error("Filter.Matches() should not match todo with text content in a different case")
+		}
+	})
+
+	t.Run("matches text content fuzzily", func(t *testing.T) {
+		filter := Filter{Text: "tsttd", Fuzzy: true}
+		if !filter.Matches(todo) {
+			t.Error("Filter.Matches() should fuzzily match todo text content")
 		}
 	})
 
 	t.Run("does not match non-matching done status", func(t *testing.T) {
 		filter := Filter{Done: "true"}
-		if filter.matches(todo) {
-			t.Error("Filter.matches() should not match todo with non-matching done status")
+		if filter.Matches(todo) {
+			t.Error("Filter.Matches() should not match todo with non-matching done status")
 		}
 	})
 
 	t.Run("does not match non-matching priority", func(t *testing.T) {
-		filter := Filter{Priority: "B"}
-		if filter.matches(todo) {
-			t.Error("Filter.matches() should not match todo with non-matching priority")
+		filter := Filter{Priorities: Set{Any: []string{"B"}}}
+		if filter.Matches(todo) {
+			t.Error("Filter.Matches() should not match todo with non-matching priority")
 		}
 	})
 
 	t.Run("does not match non-matching project", func(t *testing.T) {
-		filter := Filter{Project: "+project2"}
-		if filter.matches(todo) {
-			t.Error("Filter.matches() should not match todo with non-matching project")
+		filter := Filter{Projects: Set{All: []string{"+project2"}}}
+		if filter.Matches(todo) {
+			t.Error("Filter.Matches() should not match todo with non-matching project")
 		}
 	})
 
 	t.Run("does not match non-matching context", func(t *testing.T) {
-		filter := Filter{Context: "@context2"}
-		if filter.matches(todo) {
-			t.Error("Filter.matches() should not match todo with non-matching context")
+		filter := Filter{Contexts: Set{All: []string{"@context2"}}}
+		if filter.Matches(todo) {
+			t.Error("Filter.Matches() should not match todo with non-matching context")
 		}
 	})
 
 	t.Run("does not match non-matching text content", func(t *testing.T) {
 		filter := Filter{Text: "nonexistent"}
-		if filter.matches(todo) {
-			t.Error("Filter.matches() should not match todo with non-matching text content")
+		if filter.Matches(todo) {
+			t.Error("Filter.Matches() should not match todo with non-matching text content")
 		}
 	})
 }
+
+func TestFilter_Due(t *testing.T) {
+	today := time.Date(2024, 3, 10, 15, 0, 0, 0, time.Local)
+	todos := []Todo{
+		NewTodo("overdue task due:2024-03-01"),
+		NewTodo("due today due:2024-03-10"),
+		NewTodo("due soon due:2024-03-13"),
+		NewTodo("due later due:2024-04-01"),
+		NewTodo("no due date"),
+		NewTodo("invalid due date due:someday"),
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{
+			name:   "overdue",
+			filter: Filter{Overdue: true, Today: today},
+			want:   []string{"overdue task due:2024-03-01"},
+		},
+		{
+			name:   "due today",
+			filter: Filter{DueToday: true, Today: today},
+			want:   []string{"due today due:2024-03-10"},
+		},
+		{
+			name:   "due within",
+			filter: Filter{DueWithin: 3, Today: today},
+			want:   []string{"due today due:2024-03-10", "due soon due:2024-03-13"},
+		},
+		{
+			name:   "overdue or due within",
+			filter: Filter{Overdue: true, DueWithin: 3, Today: today},
+			want:   []string{"overdue task due:2024-03-01", "due today due:2024-03-10", "due soon due:2024-03-13"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := tt.filter.Apply(todos)
+			got := make([]string, len(filtered))
+			for i, todo := range filtered {
+				got[i] = todo.Text
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Filter.Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_HideFuture(t *testing.T) {
+	today := time.Date(2024, 3, 10, 0, 0, 0, 0, time.Local)
+	todos := []Todo{
+		NewTodo("actionable t:2024-03-10"),
+		NewTodo("parked t:2024-04-01"),
+		NewTodo("no threshold"),
+	}
+
+	filtered := Filter{HideFuture: true, Today: today}.Apply(todos)
+	if len(filtered) != 2 {
+		t.Fatalf("Filter.Apply() returned %d todos, want 2", len(filtered))
+	}
+	for _, todo := range filtered {
+		if todo.Text == "parked t:2024-04-01" {
+			t.Error("Filter.Apply() returned todo with a future threshold date")
+		}
+	}
+
+	if filtered := (Filter{Today: today}).Apply(todos); len(filtered) != 3 {
+		t.Errorf("Filter.Apply() without HideFuture returned %d todos, want 3", len(filtered))
+	}
+}
+
+func TestFilter_Rank(t *testing.T) {
+	todos := []Todo{
+		NewTodo("call mom about lunch"),
+		NewTodo("buy milk"),
+		NewTodo("make lists"),
+	}
+
+	got := []string{}
+	for _, todo := range (Filter{Text: "ml", Fuzzy: true, Rank: true}).Apply(todos) {
+		got = append(got, todo.Text)
+	}
+	want := []string{"make lists", "buy milk", "call mom about lunch"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Filter.Apply() = %v, want %v", got, want)
+	}
+
+	got = []string{}
+	for _, todo := range (Filter{Text: "ml", Fuzzy: true}).Apply(todos) {
+		got = append(got, todo.Text)
+	}
+	want = []string{"call mom about lunch", "buy milk", "make lists"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Filter.Apply() without Rank = %v, want %v", got, want)
+	}
+}
+
+func TestFilter_Sets(t *testing.T) {
+	todos := []Todo{
+		NewTodo("(A) Cut release +release @review"),
+		NewTodo("(B) Call printer +release @phone waiting:bob"),
+		NewTodo("Buy stamps @errands"),
+		NewTodo("(C) Write notes +docs @review waiting:alice"),
+		NewTodo("Plan offsite"),
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []int // indices of the matching todos
+	}{
+		{name: "all projects and contexts", filter: Filter{Projects: Set{All: []string{"+release"}}, Contexts: Set{All: []string{"@review"}}}, want: []int{0}},
+		{name: "all contexts", filter: Filter{Contexts: Set{All: []string{"@review", "@phone"}}}, want: []int{}},
+		{name: "any context", filter: Filter{Contexts: Set{Any: []string{"@phone", "@errands"}}}, want: []int{1, 2}},
+		{name: "no context", filter: Filter{Contexts: Set{None: []string{"@review", "@phone"}}}, want: []int{2, 4}},
+		{name: "any and none", filter: Filter{Projects: Set{Any: []string{"+release", "+docs"}, None: []string{"+docs"}}}, want: []int{0, 1}},
+		{name: "ignores case", filter: Filter{Projects: Set{All: []string{"+Release"}}}, want: []int{0, 1}},
+		{name: "match case", filter: Filter{Projects: Set{All: []string{"+Release"}}, Case: MatchCase}, want: []int{}},
+		{name: "any priority", filter: Filter{Priorities: Set{Any: []string{"A", "C"}}}, want: []int{0, 3}},
+		{name: "no priority", filter: Filter{Priorities: Set{None: []string{"A", "B", "C"}}}, want: []int{2, 4}},
+		{name: "tag key", filter: Filter{Tags: Set{All: []string{"waiting"}}}, want: []int{1, 3}},
+		{name: "tag value", filter: Filter{Tags: Set{Any: []string{"waiting:bob"}}}, want: []int{1}},
+		{name: "no tag", filter: Filter{Tags: Set{None: []string{"waiting"}}}, want: []int{0, 2, 4}},
+		{name: "empty sets", filter: Filter{}, want: []int{0, 1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []int{}
+			for i, todo := range todos {
+				if tt.filter.Matches(todo) {
+					got = append(got, i)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Filter.Matches() matched %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
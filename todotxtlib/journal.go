@@ -0,0 +1,225 @@
+package todotxtlib
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"time"
+)
+
+// maxJournalEntries is the number of operations kept in a journal
+const maxJournalEntries = 100
+
+// ErrNothingToUndo is returned by Undo when there are no operations left to undo
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// ErrNothingToRedo is returned by Redo when there are no undone operations to redo
+var ErrNothingToRedo = errors.New("nothing to redo")
+
+// JournalEntry records the lines an operation removed from the todo list and the
+// lines it added, a changed todo being both removed and added
+type JournalEntry struct {
+	Operation string        `json:"operation"`
+	Time      time.Time     `json:"time"`
+	Removed   []JournalLine `json:"removed,omitempty"`
+	Added     []JournalLine `json:"added,omitempty"`
+}
+
+// JournalLine is a line of the todo list and its 0-based position, before the
+// operation for removed lines and after it for added lines
+type JournalLine struct {
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+}
+
+// newJournalEntry returns an entry for an operation that changed the todo list
+// from before to after
+func newJournalEntry(operation string, time time.Time, before, after []string) JournalEntry {
+	changes := diffLines(before, after)
+	removed := slices.Clone(changes.deleted)
+	added := slices.Clone(changes.inserted)
+	for _, pair := range changes.modified {
+		removed = append(removed, pair.from)
+		added = append(added, pair.to)
+	}
+
+	return JournalEntry{
+		Operation: operation,
+		Time:      time,
+		Removed:   locateLines(before, removed),
+		Added:     locateLines(after, added),
+	}
+}
+
+// locateLines returns the texts with their positions in lines, in order of position.
+// A text that occurs several times is given the positions of its last copies, as the
+// earlier ones are the copies that diffLines matched with the other list
+func locateLines(lines, texts []string) []JournalLine {
+	used := make([]bool, len(lines))
+	located := []JournalLine{}
+	for _, text := range texts {
+		for i := len(lines) - 1; i >= 0; i-- {
+			if !used[i] && lines[i] == text {
+				used[i] = true
+				located = append(located, JournalLine{Index: i, Text: text})
+				break
+			}
+		}
+	}
+	slices.SortFunc(located, func(a, b JournalLine) int { return cmp.Compare(a.Index, b.Index) })
+	return located
+}
+
+// applyJournalLines returns lines without the lines in remove and with the lines in
+// insert at their positions, which undoes an entry given its added and removed
+// lines and redoes it given its removed and added lines. A line to remove is
+// looked up elsewhere if the lines have moved since; lines to remove that are
+// gone, because they have been changed since, are returned as conflicts
+func applyJournalLines(lines []string, remove, insert []JournalLine) ([]string, []string) {
+	removed := make([]bool, len(lines))
+	conflicts := []string{}
+	for _, line := range remove {
+		index := -1
+		if line.Index >= 0 && line.Index < len(lines) && !removed[line.Index] && lines[line.Index] == line.Text {
+			index = line.Index
+		} else {
+			for i, text := range lines {
+				if !removed[i] && text == line.Text {
+					index = i
+					break
+				}
+			}
+		}
+		if index < 0 {
+			conflicts = append(conflicts, line.Text)
+			continue
+		}
+		removed[index] = true
+	}
+
+	result := make([]string, 0, len(lines)+len(insert))
+	for i, line := range lines {
+		if !removed[i] {
+			result = append(result, line)
+		}
+	}
+	for _, line := range insert {
+		result = slices.Insert(result, min(max(line.Index, 0), len(result)), line.Text)
+	}
+	return result, conflicts
+}
+
+// Journal stores the history of operations used to undo and redo them. The
+// position is the number of entries that are currently applied; entries after
+// it have been undone and can be redone.
+type Journal interface {
+	Load() (entries []JournalEntry, position int, err error)
+	Store(entries []JournalEntry, position int) error
+}
+
+// journalFile is the content of a journal file
+type journalFile struct {
+	Position int            `json:"position"`
+	Entries  []JournalEntry `json:"entries"`
+}
+
+// NewFileJournal returns a new Journal for the todo.txt file at todoTxtPath, stored
+// in a JSON file with a .journal suffix next to it. Like the .bak file, the journal
+// holds the text of todos, so it is written with the permissions of todo.txt
+func NewFileJournal(todoTxtPath string) Journal {
+	if resolved, err := resolveSymlinks(todoTxtPath); err == nil {
+		todoTxtPath = resolved
+	}
+	return &fileJournal{
+		path:        todoTxtPath + ".journal",
+		todoTxtPath: todoTxtPath,
+	}
+}
+
+// fileJournal is a Journal stored in a JSON file
+type fileJournal struct {
+	path        string
+	todoTxtPath string
+}
+
+// Load reads the journal from the file, which is empty if the file does not exist
+func (j *fileJournal) Load() ([]JournalEntry, int, error) {
+	content, err := os.ReadFile(j.path)
+	if os.IsNotExist(err) {
+		return []JournalEntry{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var file journalFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, 0, err
+	}
+	return file.Entries, file.Position, nil
+}
+
+// Store replaces the journal file with the given entries and position
+func (j *fileJournal) Store(entries []JournalEntry, position int) error {
+	content, err := json.Marshal(journalFile{Position: position, Entries: entries})
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(j.todoTxtPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return writeFileAtomic(j.path, content, mode)
+}
+
+// journalChange is a change to a journal that waits until the todos it belongs to
+// are saved: either an operation to add, or an operation undone or redone
+type journalChange struct {
+	entry JournalEntry // operation to add, discarding any undone operations
+	move  int          // -1 to undo or 1 to redo an operation instead
+}
+
+// applyJournalChanges returns the entries and position of a journal after the given changes
+func applyJournalChanges(entries []JournalEntry, position int, changes []journalChange) ([]JournalEntry, int) {
+	for _, change := range changes {
+		if change.move != 0 {
+			position = min(max(position+change.move, 0), len(entries))
+			continue
+		}
+
+		entries = append(entries[:position:position], change.entry)
+		if len(entries) > maxJournalEntries {
+			entries = entries[len(entries)-maxJournalEntries:]
+		}
+		position = len(entries)
+	}
+	return entries, position
+}
+
+// NewMemoryJournal returns a new Journal kept in memory
+func NewMemoryJournal() Journal {
+	return &memoryJournal{
+		entries: []JournalEntry{},
+	}
+}
+
+// memoryJournal is a Journal kept in memory
+type memoryJournal struct {
+	entries  []JournalEntry
+	position int
+}
+
+// Load returns the entries and position kept in memory
+func (j *memoryJournal) Load() ([]JournalEntry, int, error) {
+	return j.entries, j.position, nil
+}
+
+// Store keeps the given entries and position in memory
+func (j *memoryJournal) Store(entries []JournalEntry, position int) error {
+	j.entries = entries
+	j.position = position
+	return nil
+}
@@ -0,0 +1,141 @@
+package todotxtlib
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFileJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.txt")
+	journal := NewFileJournal(path)
+
+	entries, position, err := journal.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(entries) != 0 || position != 0 {
+		t.Errorf("Load() = %v, %d, want an empty journal for a missing file", entries, position)
+	}
+
+	stored := []JournalEntry{
+		{Operation: "add todos", Time: time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC), Added: []JournalLine{{Index: 0, Text: "task one"}}},
+		{Operation: "toggle todos", Time: time.Date(2024, 1, 15, 9, 31, 0, 0, time.UTC),
+			Removed: []JournalLine{{Index: 0, Text: "task one"}}, Added: []JournalLine{{Index: 0, Text: "x 2024-01-15 task one"}}},
+	}
+	if err := journal.Store(stored, 1); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	entries, position, err = NewFileJournal(path).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if position != 1 {
+		t.Errorf("Load() position = %d, want 1", position)
+	}
+	if len(entries) != 2 || entries[1].Operation != "toggle todos" || !slices.Equal(entries[1].Added, stored[1].Added) || !entries[0].Time.Equal(stored[0].Time) {
+		t.Errorf("Load() entries = %+v, want %+v", entries, stored)
+	}
+}
+
+func TestFileJournal_Mode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(path, []byte("task one\n"), 0600); err != nil {
+		t.Fatalf("failed to write todo.txt: %v", err)
+	}
+
+	if err := NewFileJournal(path).Store([]JournalEntry{{Operation: "add todos"}}, 1); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	info, err := os.Stat(path + ".journal")
+	if err != nil {
+		t.Fatalf("failed to stat journal: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("journal mode = %v, want the mode of todo.txt, -rw-------", info.Mode().Perm())
+	}
+}
+
+func TestJournalEntry_UndoRedo(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []string
+		after  []string
+	}{
+		{name: "add", before: []string{"a", "b"}, after: []string{"a", "c", "b"}},
+		{name: "remove", before: []string{"a", "b", "c"}, after: []string{"a", "c"}},
+		{name: "modify", before: []string{"(A) a", "b"}, after: []string{"(B) a", "b"}},
+		{name: "duplicates", before: []string{"a", "b", "a"}, after: []string{"a", "b"}},
+		{name: "everything", before: []string{"a", "b", "c"}, after: []string{"x 2024-01-15 a", "d", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := newJournalEntry("operation", time.Time{}, tt.before, tt.after)
+
+			undone, conflicts := applyJournalLines(tt.after, entry.Added, entry.Removed)
+			if len(conflicts) > 0 || !slices.Equal(undone, tt.before) {
+				t.Errorf("undo = %q, conflicts %q, want %q", undone, conflicts, tt.before)
+			}
+			redone, conflicts := applyJournalLines(tt.before, entry.Removed, entry.Added)
+			if len(conflicts) > 0 || !slices.Equal(redone, tt.after) {
+				t.Errorf("redo = %q, conflicts %q, want %q", redone, conflicts, tt.after)
+			}
+		})
+	}
+}
+
+func TestJournalEntry_OnlyChangedLines(t *testing.T) {
+	entry := newJournalEntry("toggle todos", time.Time{}, []string{"a", "b", "c"}, []string{"a", "x b", "c"})
+
+	if want := []JournalLine{{Index: 1, Text: "b"}}; !slices.Equal(entry.Removed, want) {
+		t.Errorf("Removed = %v, want %v", entry.Removed, want)
+	}
+	if want := []JournalLine{{Index: 1, Text: "x b"}}; !slices.Equal(entry.Added, want) {
+		t.Errorf("Added = %v, want %v", entry.Added, want)
+	}
+}
+
+func TestApplyJournalLines_Conflict(t *testing.T) {
+	_, conflicts := applyJournalLines([]string{"(B) a"}, []JournalLine{{Index: 0, Text: "(A) a"}}, []JournalLine{{Index: 0, Text: "a"}})
+
+	if want := []string{"(A) a"}; !slices.Equal(conflicts, want) {
+		t.Errorf("conflicts = %q, want %q", conflicts, want)
+	}
+}
+
+func TestApplyJournalChanges(t *testing.T) {
+	entry := func(operation string) JournalEntry {
+		return JournalEntry{Operation: operation}
+	}
+	operations := func(entries []JournalEntry) []string {
+		names := make([]string, len(entries))
+		for i, entry := range entries {
+			names[i] = entry.Operation
+		}
+		return names
+	}
+
+	stored := []JournalEntry{entry("one"), entry("two")}
+	entries, position := applyJournalChanges(stored, 1, []journalChange{
+		{entry: entry("three")},
+		{move: -1},
+		{move: -1},
+		{move: -1},
+		{move: 1},
+	})
+
+	if want := []string{"one", "three"}; !slices.Equal(operations(entries), want) {
+		t.Errorf("applyJournalChanges() entries = %v, want %v", operations(entries), want)
+	}
+	if position != 1 {
+		t.Errorf("applyJournalChanges() position = %d, want 1", position)
+	}
+	if stored[1].Operation != "two" {
+		t.Errorf("applyJournalChanges() changed the stored entries to %v", operations(stored))
+	}
+}
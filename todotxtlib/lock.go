@@ -0,0 +1,27 @@
+package todotxtlib
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLockTimeout is returned when the lock on a todo.txt file could not be
+// acquired in time, usually because another togodo is using the file
+var ErrLockTimeout = errors.New("timed out waiting for lock")
+
+// lockPollInterval is how often a held lock is retried until the timeout
+const lockPollInterval = 25 * time.Millisecond
+
+// Locker is implemented by repositories that lock their file while reading and
+// saving it, see WithLocking
+type Locker interface {
+	// Lock holds the lock until the returned function is called, so that several
+	// reads and saves happen without other processes changing the file in between
+	Lock() (unlock func(), err error)
+}
+
+// lockTimeoutError returns the error for a lock on path that was not acquired in time
+func lockTimeoutError(path string, timeout time.Duration) error {
+	return fmt.Errorf("%w on %s after %s, is another togodo using the file?", ErrLockTimeout, path, timeout)
+}
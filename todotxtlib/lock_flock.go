@@ -0,0 +1,47 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package todotxtlib
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileLock is an exclusive advisory lock held on a lock file
+type fileLock struct {
+	file *os.File
+}
+
+// acquireLock takes an exclusive flock on the file at path, creating it if
+// needed, and waits up to timeout for other processes to release it
+func acquireLock(path string, timeout time.Duration) (*fileLock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			return &fileLock{file: file}, nil
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) && !errors.Is(err, syscall.EINTR) {
+			file.Close()
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, lockTimeoutError(path, timeout)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// release releases the lock. The lock file is left in place, as removing it
+// could let two processes lock different files at the same path
+func (l *fileLock) release() error {
+	return l.file.Close()
+}
@@ -0,0 +1,237 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package todotxtlib
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAcquireLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.txt.lock")
+
+	lock, err := acquireLock(path, time.Second)
+	if err != nil {
+		t.Fatalf("acquireLock() error = %v", err)
+	}
+
+	t.Run("times out while the lock is held", func(t *testing.T) {
+		_, err := acquireLock(path, 50*time.Millisecond)
+		if !errors.Is(err, ErrLockTimeout) {
+			t.Errorf("acquireLock() error = %v, want ErrLockTimeout", err)
+		}
+	})
+
+	t.Run("waits for the lock to be released", func(t *testing.T) {
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			lock.release()
+		}()
+
+		second, err := acquireLock(path, time.Second)
+		if err != nil {
+			t.Fatalf("acquireLock() error = %v", err)
+		}
+		second.release()
+	})
+}
+
+func TestRepository_WithLocking(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(path, []byte("task one\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	repo, err := NewFileRepository(NewFileReader(path), NewFileWriter(path), WithLocking(path, 50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewFileRepository() error = %v", err)
+	}
+	repo.Add("task two")
+
+	lock, err := acquireLock(path+".lock", time.Second)
+	if err != nil {
+		t.Fatalf("acquireLock() error = %v", err)
+	}
+
+	if err := repo.Save(); !errors.Is(err, ErrLockTimeout) {
+		t.Errorf("Save() error = %v, want ErrLockTimeout while another process holds the lock", err)
+	}
+
+	lock.release()
+
+	if err := repo.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	if string(content) != "task one\ntask two\n" {
+		t.Errorf("file content = %q, want %q", string(content), "task one\ntask two\n")
+	}
+
+	if _, err := NewFileRepository(NewFileReader(path), NewFileWriter(path), WithLocking(path, time.Second)); err != nil {
+		t.Errorf("NewFileRepository() error = %v after the lock was released", err)
+	}
+}
+
+func TestRepository_LockHeldAcrossReadAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(path, []byte("task one\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	open := func(timeout time.Duration) (TodoRepository, error) {
+		return NewFileRepository(NewFileReader(path), NewFileWriter(path), WithLocking(path, timeout))
+	}
+
+	first, err := open(time.Second)
+	if err != nil {
+		t.Fatalf("NewFileRepository() error = %v", err)
+	}
+	unlock, err := first.(Locker).Lock()
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	// Another repository cannot read the file until the change is saved
+	if _, err := open(50 * time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Errorf("NewFileRepository() error = %v, want ErrLockTimeout while the lock is held", err)
+	}
+
+	// Saving while holding the lock does not wait for it
+	first.Add("task two")
+	if err := first.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	unlock()
+	unlock() // releasing twice does nothing
+
+	second, err := open(time.Second)
+	if err != nil {
+		t.Fatalf("NewFileRepository() error = %v after the lock was released", err)
+	}
+	todos, _ := second.ListAll()
+	if lines := todoLines(todos); len(lines) != 2 || lines[1] != "task two" {
+		t.Errorf("second repository read %v, want the saved change", lines)
+	}
+}
+
+func TestRepository_OverlappingChangesAreMerged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(path, []byte("task one\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	open := func() TodoRepository {
+		repo, err := NewFileRepository(NewFileReader(path), NewFileWriter(path), WithLocking(path, time.Second))
+		if err != nil {
+			t.Fatalf("NewFileRepository() error = %v", err)
+		}
+		return repo
+	}
+
+	// Without holding the lock, both repositories read the same version, and the
+	// second save merges in the first instead of overwriting it
+	first, second := open(), open()
+	first.Add("task two")
+	second.Add("task three")
+	if err := first.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := second.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	if want := "task one\ntask two\ntask three\n"; string(content) != want {
+		t.Errorf("file content = %q, want %q", string(content), want)
+	}
+}
+
+func TestService_LockReloads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(path, []byte("task one\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	repo, err := NewFileRepository(NewFileReader(path), NewFileWriter(path), WithLocking(path, time.Second))
+	if err != nil {
+		t.Fatalf("NewFileRepository() error = %v", err)
+	}
+	service := NewTodoService(repo)
+
+	// Changed by another process after the repository was loaded
+	if err := os.WriteFile(path, []byte("task one\ntask two\n"), 0644); err != nil {
+		t.Fatalf("Failed to change test file: %v", err)
+	}
+
+	unlock, err := service.Lock()
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	defer unlock()
+
+	todos, _ := service.FilterTodos(Filter{})
+	if len(todos) != 2 {
+		t.Errorf("Lock() should pick up changes made before it was taken, got %d todos", len(todos))
+	}
+}
+
+func TestService_ConcurrentJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// Each service stands in for a separate togodo process, as flock locks
+	// taken through different open files exclude each other
+	const processes = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, processes)
+	for i := range processes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			repo, err := NewFileRepository(NewFileReader(path), NewFileWriter(path), WithLocking(path, 5*time.Second))
+			if err != nil {
+				errs <- err
+				return
+			}
+			service := NewTodoService(repo, WithJournal(slowJournal{NewFileJournal(path)}))
+			if _, err := service.AddTodos([]string{fmt.Sprintf("task %d", i)}); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("AddTodos() error = %v", err)
+	}
+
+	entries, position, err := NewFileJournal(path).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(entries) != processes || position != processes {
+		t.Errorf("journal has %d entries at position %d, want %d of each", len(entries), position, processes)
+	}
+}
+
+// slowJournal is a Journal that takes a while to store, so that concurrent
+// operations would overlap if the journal was not locked
+type slowJournal struct {
+	Journal
+}
+
+// Store waits briefly before storing the journal
+func (j slowJournal) Store(entries []JournalEntry, position int) error {
+	time.Sleep(50 * time.Millisecond)
+	return j.Journal.Store(entries, position)
+}
@@ -0,0 +1,18 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package todotxtlib
+
+import "time"
+
+// fileLock is a no-op lock on platforms without flock
+type fileLock struct{}
+
+// acquireLock does nothing, as advisory locking is only supported on platforms with flock
+func acquireLock(path string, timeout time.Duration) (*fileLock, error) {
+	return &fileLock{}, nil
+}
+
+// release does nothing
+func (l *fileLock) release() error {
+	return nil
+}
@@ -0,0 +1,156 @@
+package todotxtlib
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// caseMode controls whether searches tell upper and lower case letters apart
+type caseMode int
+
+const (
+	IgnoreCase caseMode = iota // "milk" matches "Milk" and "MILK"
+	SmartCase                  // ignore case unless the search text contains an upper case letter
+	MatchCase                  // match case exactly
+)
+
+// Scores of a text match, used to rank the best matches first
+const (
+	scoreMatch       = 16 // each matched character
+	bonusBoundary    = 8  // a match at the start of a word
+	bonusConsecutive = 8  // a match right after the previous one
+	penaltyGapStart  = 3  // a gap between two matches
+	penaltyGapExtend = 1  // each character skipped after the first in a gap
+)
+
+// foldsCase reports whether searching for text ignores case
+func (m caseMode) foldsCase(text string) bool {
+	switch m {
+	case MatchCase:
+		return false
+	case SmartCase:
+		return !strings.ContainsFunc(text, unicode.IsUpper)
+	}
+	return true
+}
+
+// equal reports whether a value equals the search text under the case mode
+func (m caseMode) equal(value, text string) bool {
+	if m.foldsCase(text) {
+		return normalizeText(value, true) == normalizeText(text, true)
+	}
+	return normalizeText(value, false) == normalizeText(text, false)
+}
+
+// normalizeText brings text into a canonical Unicode form, so that e.g. a
+// precomposed é matches an e followed by a combining accent, and folds its
+// case if foldCase is set
+func normalizeText(text string, foldCase bool) string {
+	text = norm.NFKC.String(text)
+	if foldCase {
+		text = cases.Fold().String(text)
+	}
+	return text
+}
+
+// textMatcher matches search text against the text of todos, either as a
+// substring or fuzzily, with the characters in order but not necessarily
+// adjacent
+type textMatcher struct {
+	needle   string
+	foldCase bool
+	fuzzy    bool
+}
+
+// newTextMatcher returns a textMatcher for the search text
+func newTextMatcher(text string, mode caseMode, fuzzy bool) textMatcher {
+	foldCase := mode.foldsCase(text)
+	return textMatcher{
+		needle:   normalizeText(text, foldCase),
+		foldCase: foldCase,
+		fuzzy:    fuzzy,
+	}
+}
+
+// score reports whether the text matches, and how well. The score is zero if
+// the search text is found at the start of a word, and lower the further its
+// characters are spread out, so scores for search texts of different lengths
+// can be compared
+func (m textMatcher) score(text string) (int, bool) {
+	if m.needle == "" {
+		return 0, true
+	}
+	haystack := normalizeText(text, m.foldCase)
+
+	if !m.fuzzy {
+		index := strings.Index(haystack, m.needle)
+		if index < 0 {
+			return 0, false
+		}
+		runes := []rune(haystack)
+		start := utf8.RuneCountInString(haystack[:index])
+		positions := make([]int, utf8.RuneCountInString(m.needle))
+		for i := range positions {
+			positions[i] = start + i
+		}
+		return scorePositions(runes, positions), true
+	}
+
+	runes := []rune(haystack)
+	needle := []rune(m.needle)
+
+	// Find the first window containing the characters in order
+	end, matched := -1, 0
+	for i, r := range runes {
+		if r == needle[matched] {
+			matched++
+			if matched == len(needle) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, false
+	}
+
+	// Narrow the window by matching backwards from its end
+	positions := make([]int, len(needle))
+	matched = len(needle) - 1
+	for i := end; matched >= 0; i-- {
+		if runes[i] == needle[matched] {
+			positions[matched] = i
+			matched--
+		}
+	}
+	return scorePositions(runes, positions), true
+}
+
+// scorePositions scores a match of the characters at the given positions,
+// relative to a match of as many adjacent characters at the start of a word
+func scorePositions(runes []rune, positions []int) int {
+	score := -(len(positions)*(scoreMatch+bonusConsecutive) - bonusConsecutive + bonusBoundary)
+	for i, position := range positions {
+		score += scoreMatch
+		if position == 0 || !isWordRune(runes[position-1]) {
+			score += bonusBoundary
+		}
+		if i > 0 {
+			if gap := position - positions[i-1] - 1; gap == 0 {
+				score += bonusConsecutive
+			} else {
+				score -= penaltyGapStart + penaltyGapExtend*(gap-1)
+			}
+		}
+	}
+	return score
+}
+
+// isWordRune reports whether r is part of a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,64 @@
+package todotxtlib
+
+import (
+	"testing"
+)
+
+func TestTextMatcher_Score(t *testing.T) {
+	tests := []struct {
+		name   string
+		needle string
+		text   string
+		mode   caseMode
+		fuzzy  bool
+		want   bool
+	}{
+		{name: "ignore case", needle: "milk", text: "Buy Milk", want: true},
+		{name: "ignore case upper needle", needle: "MILK", text: "buy milk", want: true},
+		{name: "unicode case folding", needle: "straße", text: "STRASSE fegen", want: true},
+		{name: "unicode normalization", needle: "café", text: "Café au lait", want: true},
+		{name: "compatibility normalization", needle: "file", text: "ﬁle taxes", want: true},
+		{name: "smart case lower needle", needle: "milk", text: "Buy Milk", mode: SmartCase, want: true},
+		{name: "smart case upper needle", needle: "Milk", text: "buy milk", mode: SmartCase, want: false},
+		{name: "smart case exact", needle: "Milk", text: "Buy Milk", mode: SmartCase, want: true},
+		{name: "match case", needle: "milk", text: "Buy Milk", mode: MatchCase, want: false},
+		{name: "substring not fuzzy", needle: "bmk", text: "buy milk", want: false},
+		{name: "fuzzy", needle: "bmk", text: "buy milk", fuzzy: true, want: true},
+		{name: "fuzzy out of order", needle: "kmb", text: "buy milk", fuzzy: true, want: false},
+		{name: "fuzzy ignore case", needle: "BMK", text: "buy milk", fuzzy: true, want: true},
+		{name: "empty needle", needle: "", text: "anything", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got := newTextMatcher(tt.needle, tt.mode, tt.fuzzy).score(tt.text)
+			if got != tt.want {
+				t.Errorf("score(%q) in %q = %v, want %v", tt.needle, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextMatcher_Ranking(t *testing.T) {
+	matcher := newTextMatcher("rel", IgnoreCase, true)
+
+	// Each text is expected to score higher than the next
+	texts := []string{
+		"release notes",                  // consecutive at the start of a word
+		"fix the barrel",                 // consecutive inside a word
+		"read every letter",              // close together
+		"rent paid, then email the bank", // far apart
+	}
+
+	previous := 0
+	for i, text := range texts {
+		score, ok := matcher.score(text)
+		if !ok {
+			t.Fatalf("score(%q) did not match", text)
+		}
+		if i > 0 && score >= previous {
+			t.Errorf("score(%q) = %d, want less than %d for %q", text, score, previous, texts[i-1])
+		}
+		previous = score
+	}
+}
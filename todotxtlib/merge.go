@@ -0,0 +1,153 @@
+package todotxtlib
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ConflictError is returned by Save when the file was changed by another
+// program in a way that conflicts with the unsaved changes
+type ConflictError struct {
+	Lines []string // lines of the file as last loaded that were changed on both sides
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("todo.txt was changed by another program in a way that conflicts with your changes to: %s",
+		strings.Join(e.Lines, "; "))
+}
+
+// linePair is a line that was changed into another line
+type linePair struct {
+	from string
+	to   string
+}
+
+// lineChanges are the changes made to a list of lines, ignoring their order
+type lineChanges struct {
+	deleted  []string   // lines that were removed
+	modified []linePair // lines that were changed, e.g. completed or reprioritised
+	inserted []string   // lines that were added
+}
+
+// diffLines returns the changes that turn base into changed. A removed line and an
+// added line with the same text apart from the done marker, dates and priority are
+// treated as a modification of that line.
+func diffLines(base, changed []string) lineChanges {
+	counts := map[string]int{}
+	for _, line := range base {
+		counts[line]++
+	}
+
+	added := []string{}
+	for _, line := range changed {
+		if counts[line] > 0 {
+			counts[line]--
+		} else {
+			added = append(added, line)
+		}
+	}
+
+	changes := lineChanges{}
+	for _, line := range base {
+		if counts[line] == 0 {
+			continue
+		}
+		counts[line]--
+
+		index := slices.IndexFunc(added, func(a string) bool { return lineBody(a) == lineBody(line) })
+		if index < 0 {
+			changes.deleted = append(changes.deleted, line)
+			continue
+		}
+		changes.modified = append(changes.modified, linePair{from: line, to: added[index]})
+		added = slices.Delete(added, index, index+1)
+	}
+	changes.inserted = added
+
+	return changes
+}
+
+// takeDeleted reports whether line was deleted, consuming the deletion
+func (c *lineChanges) takeDeleted(line string) bool {
+	index := slices.Index(c.deleted, line)
+	if index < 0 {
+		return false
+	}
+	c.deleted = slices.Delete(c.deleted, index, index+1)
+	return true
+}
+
+// takeModified returns what line was changed into, consuming the modification
+func (c *lineChanges) takeModified(line string) (string, bool) {
+	index := slices.IndexFunc(c.modified, func(p linePair) bool { return p.from == line })
+	if index < 0 {
+		return "", false
+	}
+	to := c.modified[index].to
+	c.modified = slices.Delete(c.modified, index, index+1)
+	return to, true
+}
+
+// takeInserted reports whether line was inserted, consuming the insertion
+func (c *lineChanges) takeInserted(line string) bool {
+	index := slices.Index(c.inserted, line)
+	if index < 0 {
+		return false
+	}
+	c.inserted = slices.Delete(c.inserted, index, index+1)
+	return true
+}
+
+// mergeLines merges the changes made to base in ours and theirs, keeping the order
+// of theirs and appending lines added in ours. Lines are compared as a set rather
+// than by position, as togodo reorders the file when sorting. Returns the base lines
+// that were changed differently on both sides, in which case the merge failed.
+func mergeLines(base, ours, theirs []string) ([]string, []string) {
+	oursChanges := diffLines(base, ours)
+	theirsChanges := diffLines(base, theirs)
+	merged := slices.Clone(theirs)
+	conflicts := []string{}
+
+	for _, line := range oursChanges.deleted {
+		if theirsChanges.takeDeleted(line) {
+			continue
+		}
+		if _, ok := theirsChanges.takeModified(line); ok {
+			conflicts = append(conflicts, line)
+			continue
+		}
+		if index := slices.Index(merged, line); index >= 0 {
+			merged = slices.Delete(merged, index, index+1)
+		}
+	}
+
+	for _, pair := range oursChanges.modified {
+		if theirsChanges.takeDeleted(pair.from) {
+			conflicts = append(conflicts, pair.from)
+			continue
+		}
+		if to, ok := theirsChanges.takeModified(pair.from); ok {
+			if to != pair.to {
+				conflicts = append(conflicts, pair.from)
+			}
+			continue
+		}
+		if index := slices.Index(merged, pair.from); index >= 0 {
+			merged[index] = pair.to
+		}
+	}
+
+	for _, line := range oursChanges.inserted {
+		if !theirsChanges.takeInserted(line) {
+			merged = append(merged, line)
+		}
+	}
+
+	return merged, conflicts
+}
+
+// lineBody returns the text of a todo line without its done marker, priority and dates
+func lineBody(line string) string {
+	return strings.TrimSpace(line[len(linePrefix(line)):])
+}
@@ -0,0 +1,106 @@
+package todotxtlib
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeLines(t *testing.T) {
+	tests := []struct {
+		name          string
+		base          []string
+		ours          []string
+		theirs        []string
+		want          []string
+		wantConflicts []string
+	}{
+		{
+			name:   "no changes",
+			base:   []string{"a", "b"},
+			ours:   []string{"a", "b"},
+			theirs: []string{"a", "b"},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "additions on both sides",
+			base:   []string{"a"},
+			ours:   []string{"a", "ours"},
+			theirs: []string{"a", "theirs"},
+			want:   []string{"a", "theirs", "ours"},
+		},
+		{
+			name:   "same addition on both sides",
+			base:   []string{"a"},
+			ours:   []string{"a", "new"},
+			theirs: []string{"a", "new"},
+			want:   []string{"a", "new"},
+		},
+		{
+			name:   "our reordering keeps their order",
+			base:   []string{"b", "a"},
+			ours:   []string{"a", "b"},
+			theirs: []string{"b", "a", "c"},
+			want:   []string{"b", "a", "c"},
+		},
+		{
+			name:   "deletions on both sides",
+			base:   []string{"a", "b", "c"},
+			ours:   []string{"b", "c"},
+			theirs: []string{"a", "b"},
+			want:   []string{"b"},
+		},
+		{
+			name:   "our completion with their addition",
+			base:   []string{"(A) a", "b"},
+			ours:   []string{"b", "x 2024-01-15 a"},
+			theirs: []string{"(A) a", "b", "c"},
+			want:   []string{"x 2024-01-15 a", "b", "c"},
+		},
+		{
+			name:   "same modification on both sides",
+			base:   []string{"a"},
+			ours:   []string{"x 2024-01-15 a"},
+			theirs: []string{"x 2024-01-15 a"},
+			want:   []string{"x 2024-01-15 a"},
+		},
+		{
+			name:          "different modifications of the same line",
+			base:          []string{"a", "b"},
+			ours:          []string{"x 2024-01-15 a", "b"},
+			theirs:        []string{"(B) a", "b"},
+			want:          []string{"(B) a", "b"},
+			wantConflicts: []string{"a"},
+		},
+		{
+			name:          "our modification of a line they deleted",
+			base:          []string{"a", "b"},
+			ours:          []string{"(A) a", "b"},
+			theirs:        []string{"b"},
+			want:          []string{"b"},
+			wantConflicts: []string{"a"},
+		},
+		{
+			name:          "our deletion of a line they modified",
+			base:          []string{"a", "b"},
+			ours:          []string{"b"},
+			theirs:        []string{"(A) a", "b"},
+			want:          []string{"(A) a", "b"},
+			wantConflicts: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := mergeLines(tt.base, tt.ours, tt.theirs)
+			if len(tt.wantConflicts) > 0 || len(conflicts) > 0 {
+				if !slices.Equal(conflicts, tt.wantConflicts) {
+					t.Errorf("mergeLines() conflicts = %q, want %q", conflicts, tt.wantConflicts)
+				}
+				return
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("mergeLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
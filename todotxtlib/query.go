@@ -0,0 +1,584 @@
+package todotxtlib
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var queryPriorityRe = regexp.MustCompile(`^\(([A-Z])\)`)
+var queryPriorityRangeRe = regexp.MustCompile(`^pri:([A-Za-z])(?:\.\.([A-Za-z]))?$`)
+var queryDateRe = regexp.MustCompile(`^(due|t|created|completed)(<=|>=|<|>|=)(.+)$`)
+var queryRelativeDateRe = regexp.MustCompile(`^(today|tomorrow|yesterday)(?:([+-])(\d+)([dwmy]))?$`)
+
+// Query is a parsed search query that todos can be matched against
+type Query interface {
+	// Matches reports whether the todo matches the query, with relative dates
+	// such as today+7d evaluated against today
+	Matches(todo Todo, today time.Time) bool
+}
+
+// QueryOption configures how a query matches text
+type QueryOption func(*queryOptions)
+
+// queryOptions holds the options of a query
+type queryOptions struct {
+	caseMode caseMode
+	fuzzy    bool
+}
+
+// WithCaseMode sets whether the query tells upper and lower case apart,
+// IgnoreCase by default
+func WithCaseMode(mode caseMode) QueryOption {
+	return func(o *queryOptions) {
+		o.caseMode = mode
+	}
+}
+
+// WithFuzzy makes text terms match todos containing their characters in order,
+// but not necessarily next to each other, as in fzf
+func WithFuzzy(enabled bool) QueryOption {
+	return func(o *queryOptions) {
+		o.fuzzy = enabled
+	}
+}
+
+// ParseQuery parses a search query. Terms are separated by spaces and must all
+// match unless joined by OR; NOT or a leading - negates a term, and parentheses
+// group terms. A term is one of:
+//
+//	@context, +project   a context or project
+//	(A), pri:A..C        a priority or an inclusive range of priorities
+//	x                    a done todo
+//	due<today+7d         a date comparison on due, t, created or completed using
+//	                     <, <=, =, >= or >, against YYYY-MM-DD, today, tomorrow
+//	                     or yesterday with an optional +/- offset in d, w, m or y
+//	key:value            a tag, where value may contain * wildcards
+//	/regex/              a regular expression matched against the text
+//	word, "some words"   text contained in the todo
+//
+// Text, tag values, contexts, projects and regular expressions are matched
+// case-insensitively and after Unicode normalization, unless the options say
+// otherwise. An empty query matches every todo.
+func ParseQuery(query string, opts ...QueryOption) (Query, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return andQuery{}, nil
+	}
+
+	parser := &queryParser{tokens: tokens}
+	for _, opt := range opts {
+		opt(&parser.options)
+	}
+	parsed, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token, ok := parser.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q in query", token.text)
+	}
+	return parsed, nil
+}
+
+// AllOf returns a query matching todos that match all of the given queries,
+// such as a saved view and a search typed on top of it
+func AllOf(queries ...Query) Query {
+	return andQuery(queries)
+}
+
+// queryTokenKind is the kind of a token in a search query
+type queryTokenKind int
+
+const (
+	wordToken queryTokenKind = iota
+	phraseToken
+	regexToken
+	openToken
+	closeToken
+	minusToken
+)
+
+// queryToken is a single token of a search query
+type queryToken struct {
+	kind queryTokenKind
+	text string
+}
+
+// lexQuery splits a search query into tokens
+func lexQuery(query string) ([]queryToken, error) {
+	tokens := []queryToken{}
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			// (A) is a priority rather than a group
+			if priority := queryPriorityRe.FindString(query[i:]); priority != "" && endsQueryWord(query, i+len(priority)) {
+				tokens = append(tokens, queryToken{kind: wordToken, text: priority})
+				i += len(priority)
+				continue
+			}
+			tokens = append(tokens, queryToken{kind: openToken, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: closeToken, text: ")"})
+			i++
+		case c == '-' && !endsQueryWord(query, i+1):
+			tokens = append(tokens, queryToken{kind: minusToken, text: "-"})
+			i++
+		case c == '"':
+			end := strings.IndexByte(query[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("missing closing quote in query")
+			}
+			tokens = append(tokens, queryToken{kind: phraseToken, text: query[i+1 : i+1+end]})
+			i += end + 2
+		case c == '/':
+			end := i + 1
+			for end < len(query) && query[end] != '/' {
+				if query[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(query) {
+				return nil, fmt.Errorf("missing closing / in query")
+			}
+			tokens = append(tokens, queryToken{kind: regexToken, text: query[i+1 : end]})
+			i = end + 1
+		default:
+			end := i
+			for !endsQueryWord(query, end) {
+				end++
+			}
+			tokens = append(tokens, queryToken{kind: wordToken, text: query[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// endsQueryWord reports whether a word in the query ends at position i
+func endsQueryWord(query string, i int) bool {
+	return i >= len(query) || strings.IndexByte(" \t\n\r)", query[i]) >= 0
+}
+
+// queryParser parses query tokens into a Query. OR binds loosest, then AND,
+// which is implied between adjacent terms, then NOT.
+type queryParser struct {
+	tokens  []queryToken
+	pos     int
+	options queryOptions
+}
+
+// peek returns the next token without consuming it
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// peekKeyword reports whether the next token is the given keyword
+func (p *queryParser) peekKeyword(keyword string) bool {
+	token, ok := p.peek()
+	return ok && token.kind == wordToken && token.text == keyword
+}
+
+// parseOr parses terms joined by OR
+func (p *queryParser) parseOr() (Query, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	terms := orQuery{first}
+	for p.peekKeyword("OR") {
+		p.pos++
+		term, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return terms, nil
+}
+
+// parseAnd parses adjacent terms, optionally joined by AND
+func (p *queryParser) parseAnd() (Query, error) {
+	terms := andQuery{}
+	for {
+		token, ok := p.peek()
+		if !ok || token.kind == closeToken || p.peekKeyword("OR") {
+			break
+		}
+		if p.peekKeyword("AND") {
+			p.pos++
+		}
+
+		term, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+
+	switch len(terms) {
+	case 0:
+		return nil, p.missingTerm()
+	case 1:
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+// parseNot parses a term negated by NOT or a leading -
+func (p *queryParser) parseNot() (Query, error) {
+	token, ok := p.peek()
+	if ok && (token.kind == minusToken || p.peekKeyword("NOT")) {
+		p.pos++
+		term, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notQuery{term}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a single term or a parenthesised group
+func (p *queryParser) parsePrimary() (Query, error) {
+	token, ok := p.peek()
+	if !ok || token.kind == closeToken || p.peekKeyword("AND") || p.peekKeyword("OR") {
+		return nil, p.missingTerm()
+	}
+	p.pos++
+
+	switch token.kind {
+	case openToken:
+		group, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next, ok := p.peek(); !ok || next.kind != closeToken {
+			return nil, fmt.Errorf("missing closing ) in query")
+		}
+		p.pos++
+		return group, nil
+	case phraseToken:
+		return p.textQuery(token.text), nil
+	case regexToken:
+		pattern := token.text
+		if p.options.caseMode.foldsCase(pattern) {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression /%s/ in query: %w", token.text, err)
+		}
+		return regexQuery{re}, nil
+	}
+	return p.parseWord(token.text)
+}
+
+// missingTerm returns the error for a missing term at the current position
+func (p *queryParser) missingTerm() error {
+	if token, ok := p.peek(); ok {
+		return fmt.Errorf("expected a search term before %q in query", token.text)
+	}
+	return fmt.Errorf("expected a search term at the end of the query")
+}
+
+// textQuery returns a term matching todos containing the text
+func (p *queryParser) textQuery(text string) textQuery {
+	return textQuery{newTextMatcher(text, p.options.caseMode, p.options.fuzzy)}
+}
+
+// parseWord parses a single word of a query into a term
+func (p *queryParser) parseWord(word string) (Query, error) {
+	if word == "x" {
+		return doneQuery{}, nil
+	}
+
+	if match := queryPriorityRe.FindStringSubmatch(word); match != nil {
+		return priorityQuery{from: match[1], to: match[1]}, nil
+	}
+
+	if strings.HasPrefix(word, "pri:") {
+		match := queryPriorityRangeRe.FindStringSubmatch(word)
+		if match == nil {
+			return nil, fmt.Errorf("invalid priority %q in query, expected e.g. pri:A or pri:A..C", word)
+		}
+		from, to := strings.ToUpper(match[1]), strings.ToUpper(match[2])
+		if to == "" {
+			to = from
+		}
+		if from > to {
+			from, to = to, from
+		}
+		return priorityQuery{from: from, to: to}, nil
+	}
+
+	if len(word) > 1 && word[0] == '@' {
+		return contextQuery{name: word, mode: p.options.caseMode}, nil
+	}
+	if len(word) > 1 && word[0] == '+' {
+		return projectQuery{name: word, mode: p.options.caseMode}, nil
+	}
+
+	if match := queryDateRe.FindStringSubmatch(word); match != nil {
+		return parseDateQuery(match[1], match[2], match[3])
+	}
+
+	if tag, ok := ParseTag(word); ok {
+		foldCase := p.options.caseMode.foldsCase(tag.Value)
+		return tagQuery{key: tag.Key, pattern: normalizeText(tag.Value, foldCase), foldCase: foldCase}, nil
+	}
+
+	return p.textQuery(word), nil
+}
+
+// parseDateQuery parses a comparison of a date field against a date expression
+func parseDateQuery(field, operator, value string) (Query, error) {
+	query := dateQuery{field: field, operator: operator}
+	if date := parseDate(value); !date.IsZero() {
+		query.date = date
+		return query, nil
+	}
+
+	match := queryRelativeDateRe.FindStringSubmatch(value)
+	if match == nil {
+		return nil, fmt.Errorf("invalid date %q in query, expected YYYY-MM-DD or e.g. today+7d", value)
+	}
+
+	switch match[1] {
+	case "tomorrow":
+		query.days = 1
+	case "yesterday":
+		query.days = -1
+	}
+
+	if match[2] != "" {
+		amount, err := strconv.Atoi(match[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q in query: %w", value, err)
+		}
+		if match[2] == "-" {
+			amount = -amount
+		}
+		switch match[4] {
+		case "d":
+			query.days += amount
+		case "w":
+			query.days += 7 * amount
+		case "m":
+			query.months = amount
+		case "y":
+			query.years = amount
+		}
+	}
+	return query, nil
+}
+
+// scoredQuery is implemented by queries that rank the todos they match
+type scoredQuery interface {
+	// score reports whether the todo matches, and how well; higher is better
+	score(todo Todo, today time.Time) (int, bool)
+}
+
+// scoreQuery reports whether the todo matches the query, and how well
+func scoreQuery(query Query, todo Todo, today time.Time) (int, bool) {
+	if scored, ok := query.(scoredQuery); ok {
+		return scored.score(todo, today)
+	}
+	return 0, query.Matches(todo, today)
+}
+
+// andQuery matches todos that match all of its terms, scoring the sum of their scores
+type andQuery []Query
+
+func (q andQuery) Matches(todo Todo, today time.Time) bool {
+	_, ok := q.score(todo, today)
+	return ok
+}
+
+func (q andQuery) score(todo Todo, today time.Time) (int, bool) {
+	total := 0
+	for _, term := range q {
+		score, ok := scoreQuery(term, todo, today)
+		if !ok {
+			return 0, false
+		}
+		total += score
+	}
+	return total, true
+}
+
+// orQuery matches todos that match any of its terms, scoring the best match
+type orQuery []Query
+
+func (q orQuery) Matches(todo Todo, today time.Time) bool {
+	_, ok := q.score(todo, today)
+	return ok
+}
+
+func (q orQuery) score(todo Todo, today time.Time) (int, bool) {
+	best, matched := 0, false
+	for _, term := range q {
+		if score, ok := scoreQuery(term, todo, today); ok && (!matched || score > best) {
+			best, matched = score, true
+		}
+	}
+	return best, matched
+}
+
+// notQuery matches todos that do not match its term
+type notQuery struct {
+	term Query
+}
+
+func (q notQuery) Matches(todo Todo, today time.Time) bool {
+	return !q.term.Matches(todo, today)
+}
+
+// textQuery matches todos whose text contains it
+type textQuery struct {
+	matcher textMatcher
+}
+
+func (q textQuery) Matches(todo Todo, today time.Time) bool {
+	_, ok := q.score(todo, today)
+	return ok
+}
+
+func (q textQuery) score(todo Todo, today time.Time) (int, bool) {
+	return q.matcher.score(todo.Text)
+}
+
+// regexQuery matches todos whose text matches a regular expression
+type regexQuery struct {
+	re *regexp.Regexp
+}
+
+func (q regexQuery) Matches(todo Todo, today time.Time) bool {
+	return q.re.MatchString(todo.Text)
+}
+
+// contextQuery matches todos with a context
+type contextQuery struct {
+	name string
+	mode caseMode
+}
+
+func (q contextQuery) Matches(todo Todo, today time.Time) bool {
+	return slices.ContainsFunc(todo.Contexts, func(context string) bool {
+		return q.mode.equal(context, q.name)
+	})
+}
+
+// projectQuery matches todos with a project
+type projectQuery struct {
+	name string
+	mode caseMode
+}
+
+func (q projectQuery) Matches(todo Todo, today time.Time) bool {
+	return slices.ContainsFunc(todo.Projects, func(project string) bool {
+		return q.mode.equal(project, q.name)
+	})
+}
+
+// priorityQuery matches todos with a priority in an inclusive range
+type priorityQuery struct {
+	from, to string
+}
+
+func (q priorityQuery) Matches(todo Todo, today time.Time) bool {
+	return todo.Priority != "" && todo.Priority >= q.from && todo.Priority <= q.to
+}
+
+// doneQuery matches done todos
+type doneQuery struct{}
+
+func (q doneQuery) Matches(todo Todo, today time.Time) bool {
+	return todo.Done
+}
+
+// tagQuery matches todos with a tag whose value matches a pattern
+type tagQuery struct {
+	key      string
+	pattern  string // normalized, with * matching any characters
+	foldCase bool
+}
+
+func (q tagQuery) Matches(todo Todo, today time.Time) bool {
+	for _, tag := range todo.Tags {
+		if tag.Key != q.key {
+			continue
+		}
+		if matched, err := path.Match(q.pattern, normalizeText(tag.Value, q.foldCase)); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// dateQuery compares a date of the todo against a fixed date, or against a
+// date relative to today if date is zero
+type dateQuery struct {
+	field    string // due, t, created or completed
+	operator string // <, <=, =, >= or >
+	date     time.Time
+	days     int
+	months   int
+	years    int
+}
+
+func (q dateQuery) Matches(todo Todo, today time.Time) bool {
+	var value time.Time
+	var ok bool
+	switch q.field {
+	case "due":
+		value, ok = todo.Due()
+	case "t":
+		value, ok = todo.Threshold()
+	case "created":
+		value, ok = todo.CreatedAt, !todo.CreatedAt.IsZero()
+	case "completed":
+		value, ok = todo.CompletedAt, !todo.CompletedAt.IsZero()
+	}
+	if !ok {
+		return false
+	}
+
+	date := q.date
+	if date.IsZero() {
+		date = today.AddDate(q.years, q.months, q.days)
+	}
+
+	days := daysBetween(date, value)
+	switch q.operator {
+	case "<":
+		return days < 0
+	case "<=":
+		return days <= 0
+	case ">":
+		return days > 0
+	case ">=":
+		return days >= 0
+	}
+	return days == 0
+}
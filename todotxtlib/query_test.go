@@ -0,0 +1,210 @@
+package todotxtlib
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseQuery_Matches(t *testing.T) {
+	today := time.Date(2024, 3, 10, 15, 0, 0, 0, time.Local)
+	todos := []Todo{
+		NewTodo("(A) 2024-03-01 Plan release +release @work due:2024-03-12"),
+		NewTodo("(C) Write changelog +Release @home due:2024-03-30"),
+		NewTodo("Buy milk @home"),
+		NewTodo("x 2024-03-09 (B) Fix login bug +web @work due:2024-03-08"),
+		NewTodo("Renew passport rec:1y t:2024-04-01"),
+	}
+
+	tests := []struct {
+		query string
+		want  []int // indices of the matching todos
+	}{
+		{query: "", want: []int{0, 1, 2, 3, 4}},
+		{query: "@work +release", want: []int{0}},
+		{query: "@work AND +release", want: []int{0}},
+		{query: "@work OR milk", want: []int{0, 2, 3}},
+		{query: "+release -@home", want: []int{0}},
+		{query: "+release NOT @home", want: []int{0}},
+		{query: "-(@home OR @work)", want: []int{4}},
+		{query: "(+web OR +release) @work", want: []int{0, 3}},
+		{query: "@home OR @work +web", want: []int{1, 2, 3}},
+		{query: "(A)", want: []int{0}},
+		{query: "pri:A..C", want: []int{0, 1}},
+		{query: "pri:c..a", want: []int{0, 1}},
+		{query: "pri:B", want: []int{}},
+		{query: "x", want: []int{3}},
+		{query: "due<today+7d", want: []int{0, 3}},
+		{query: "due>=today due<=today+1w", want: []int{0}},
+		{query: "due=2024-03-30", want: []int{1}},
+		{query: "due<yesterday", want: []int{3}},
+		{query: "due>today+1m", want: []int{}},
+		{query: "t>today", want: []int{4}},
+		{query: "created<today", want: []int{0}},
+		{query: "completed=yesterday", want: []int{3}},
+		{query: "rec:1y", want: []int{4}},
+		{query: "due:2024-03-1*", want: []int{0}},
+		{query: "due:*", want: []int{0, 1, 3}},
+		{query: "MILK", want: []int{2}},
+		{query: `"fix login"`, want: []int{3}},
+		{query: `"login fix"`, want: []int{}},
+		{query: "/^(buy|renew) /", want: []int{2, 4}},
+		{query: "/CHANGELOG/", want: []int{1}},
+		{query: "@HOME", want: []int{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) error = %v", tt.query, err)
+			}
+
+			got := []int{}
+			for i, todo := range todos {
+				if query.Matches(todo, today) {
+					got = append(got, i)
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseQuery(%q) matched %v, want %v", tt.query, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ParseQuery(%q) matched %v, want %v", tt.query, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseQuery_Options(t *testing.T) {
+	today := time.Date(2024, 3, 10, 0, 0, 0, 0, time.Local)
+	todo := NewTodo("Call Bob about the Report @Office +Q1 ref:ABC-1")
+
+	tests := []struct {
+		query string
+		opts  []QueryOption
+		want  bool
+	}{
+		{query: "report", want: true},
+		{query: "report", opts: []QueryOption{WithCaseMode(SmartCase)}, want: true},
+		{query: "Report", opts: []QueryOption{WithCaseMode(SmartCase)}, want: true},
+		{query: "REPORT", opts: []QueryOption{WithCaseMode(SmartCase)}, want: false},
+		{query: "report", opts: []QueryOption{WithCaseMode(MatchCase)}, want: false},
+		{query: "@office", opts: []QueryOption{WithCaseMode(SmartCase)}, want: true},
+		{query: "@OFFICE", opts: []QueryOption{WithCaseMode(SmartCase)}, want: false},
+		{query: "+q1", opts: []QueryOption{WithCaseMode(MatchCase)}, want: false},
+		{query: "ref:abc-*", want: true},
+		{query: "ref:abc-*", opts: []QueryOption{WithCaseMode(MatchCase)}, want: false},
+		{query: "/bob/", want: true},
+		{query: "/bob/", opts: []QueryOption{WithCaseMode(MatchCase)}, want: false},
+		{query: "cbrpt", want: false},
+		{query: "cbrpt", opts: []QueryOption{WithFuzzy(true)}, want: true},
+		{query: `"call report"`, opts: []QueryOption{WithFuzzy(true)}, want: true},
+	}
+
+	for _, tt := range tests {
+		query, err := ParseQuery(tt.query, tt.opts...)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q) error = %v", tt.query, err)
+		}
+		if got := query.Matches(todo, today); got != tt.want {
+			t.Errorf("ParseQuery(%q) with %d options matches = %v, want %v", tt.query, len(tt.opts), got, tt.want)
+		}
+	}
+}
+
+func TestParseQuery_Rank(t *testing.T) {
+	todos := []Todo{
+		NewTodo("read paper on tree list"),
+		NewTodo("pay tax"),
+		NewTodo("report bug"),
+		NewTodo("buy milk"),
+	}
+
+	query, err := ParseQuery("report OR tax", WithFuzzy(true))
+	if err != nil {
+		t.Fatalf("ParseQuery() error = %v", err)
+	}
+
+	got := []string{}
+	for _, todo := range (Filter{Query: query, Rank: true}).Apply(todos) {
+		got = append(got, todo.Text)
+	}
+	// Exact matches of either term rank the same, ahead of the scattered match
+	want := []string{"pay tax", "report bug", "read paper on tree list"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Filter.Apply() = %v, want %v", got, want)
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	tests := []string{
+		"(",
+		"(@work",
+		"@work)",
+		"()",
+		"@work OR",
+		"OR @work",
+		"NOT",
+		"-(",
+		`"unterminated`,
+		"/unterminated",
+		"/(/",
+		"pri:1",
+		"pri:A..",
+		"due<soon",
+		"due<today+7x",
+	}
+
+	for _, query := range tests {
+		t.Run(query, func(t *testing.T) {
+			if _, err := ParseQuery(query); err == nil {
+				t.Errorf("ParseQuery(%q) expected error, got nil", query)
+			}
+		})
+	}
+}
+
+func TestFilter_Query(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	todos, err := repo.ListAll()
+	if err != nil {
+		t.Fatalf("ListAll() error = %v", err)
+	}
+
+	query, err := ParseQuery("@context1 -x")
+	if err != nil {
+		t.Fatalf("ParseQuery() error = %v", err)
+	}
+
+	filtered := Filter{Query: query}.Apply(todos)
+	if len(filtered) != 1 {
+		t.Fatalf("Filter.Apply() returned %d todos, want 1", len(filtered))
+	}
+	if filtered[0].Text != "(A) test todo 1 +project2 @context1" {
+		t.Errorf("Filter.Apply() returned %q", filtered[0].Text)
+	}
+}
+
+func TestAllOf(t *testing.T) {
+	today := time.Date(2024, 3, 10, 0, 0, 0, 0, time.Local)
+	view, err := ParseQuery("@work OR @home")
+	if err != nil {
+		t.Fatalf("ParseQuery() error = %v", err)
+	}
+	search, err := ParseQuery("report")
+	if err != nil {
+		t.Fatalf("ParseQuery() error = %v", err)
+	}
+
+	query := AllOf(view, search)
+	if !query.Matches(NewTodo("write report @home"), today) {
+		t.Error("AllOf() should match a todo matching all queries")
+	}
+	if query.Matches(NewTodo("write report @office"), today) {
+		t.Error("AllOf() should not match a todo missing one of the queries")
+	}
+}
@@ -1,6 +1,8 @@
 package todotxtlib
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"io"
 	"os"
 	"strings"
@@ -11,6 +13,24 @@ type Reader interface {
 	Read() ([]Todo, error)
 }
 
+// VersionedReader is a Reader that can also report the version of the content it
+// read, so that changes made by other programs can be detected before saving
+type VersionedReader interface {
+	Reader
+	ReadVersion() ([]Todo, Version, error)
+}
+
+// Version identifies the content of a todo.txt file at a point in time
+type Version struct {
+	Exists bool     // whether the file existed
+	Hash   [32]byte // SHA-256 hash of the file content
+}
+
+// versionOf returns the version of the given content
+func versionOf(content []byte) Version {
+	return Version{Exists: true, Hash: sha256.Sum256(content)}
+}
+
 // NewFileReader returns a new Reader that reads from a file
 func NewFileReader(path string) Reader {
 	return &fileReader{
@@ -25,17 +45,26 @@ type fileReader struct {
 
 // Read reads the content of a todo.txt file and returns a slice of Todo structs
 func (r *fileReader) Read() (todos []Todo, err error) {
-	if _, err := os.Stat(r.path); os.IsNotExist(err) {
-		return []Todo{}, nil
-	}
+	todos, _, err = r.ReadVersion()
+	return todos, err
+}
 
-	file, err := os.Open(r.path)
+// ReadVersion reads the content of a todo.txt file and returns a slice of Todo
+// structs along with the version of the content that was read
+func (r *fileReader) ReadVersion() ([]Todo, Version, error) {
+	content, err := os.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		return []Todo{}, Version{}, nil
+	}
 	if err != nil {
-		return nil, err
+		return nil, Version{}, err
 	}
-	defer file.Close()
 
-	return readFromReader(file)
+	todos, err := readFromReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, Version{}, err
+	}
+	return todos, versionOf(content), nil
 }
 
 // NewBufferReader returns a new Reader that reads from an io.Reader
@@ -55,7 +84,9 @@ func (r *bufferReader) Read() ([]Todo, error) {
 	return readFromReader(r.reader)
 }
 
-// readFromReader reads todos from any io.Reader
+// readFromReader reads todos from any io.Reader. Blank lines are skipped, so the
+// line number of each todo is its position among the non-blank lines; togodo
+// drops blank lines when it saves, after which the two agree.
 func readFromReader(r io.Reader) ([]Todo, error) {
 	content, err := io.ReadAll(r)
 	if err != nil {
@@ -70,6 +101,7 @@ func readFromReader(r io.Reader) ([]Todo, error) {
 			continue
 		}
 		todo := NewTodo(line)
+		todo.LineNumber = len(todos) + 1
 		todos = append(todos, todo)
 	}
 
@@ -158,3 +158,15 @@ func TestBufferReader_Read(t *testing.T) {
 		})
 	}
 }
+
+func TestBufferReader_LineNumbersSkipBlankLines(t *testing.T) {
+	reader := NewBufferReader(bytes.NewBufferString("a\n\nb @x\n"))
+	got, err := reader.Read()
+	if err != nil {
+		t.Fatalf("BufferReader.Read() unexpected error: %v", err)
+	}
+
+	if len(got) != 2 || got[0].LineNumber != 1 || got[1].LineNumber != 2 {
+		t.Errorf("BufferReader.Read() line numbers = %v, want 1 and 2", got)
+	}
+}
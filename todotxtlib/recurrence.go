@@ -0,0 +1,125 @@
+package todotxtlib
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var recurrenceRe = regexp.MustCompile(`^(\+?)(\d*)([dwmyb])$`)
+
+// Recurrence describes how often a todo repeats, as set by a rec: tag such as
+// rec:1w or rec:+2m
+type Recurrence struct {
+	Strict bool // advance from the previous due date rather than the completion date
+	Amount int  // number of units between occurrences
+	Unit   byte // d(ay), w(eek), m(onth), y(ear) or b(usiness day)
+}
+
+// ParseRecurrence parses the value of a rec: tag. The amount defaults to 1 if
+// omitted, and a leading + makes the recurrence strict.
+func ParseRecurrence(value string) (Recurrence, bool) {
+	match := recurrenceRe.FindStringSubmatch(value)
+	if match == nil {
+		return Recurrence{}, false
+	}
+
+	amount := 1
+	if match[2] != "" {
+		amount, _ = strconv.Atoi(match[2])
+	}
+	if amount < 1 {
+		return Recurrence{}, false
+	}
+
+	return Recurrence{
+		Strict: match[1] == "+",
+		Amount: amount,
+		Unit:   match[3][0],
+	}, true
+}
+
+// Next returns the date of the next occurrence after the given date
+func (r Recurrence) Next(date time.Time) time.Time {
+	switch r.Unit {
+	case 'w':
+		return date.AddDate(0, 0, 7*r.Amount)
+	case 'm':
+		return addMonths(date, r.Amount)
+	case 'y':
+		return addMonths(date, 12*r.Amount)
+	case 'b':
+		for remaining := r.Amount; remaining > 0; {
+			date = date.AddDate(0, 0, 1)
+			if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+				remaining--
+			}
+		}
+		return date
+	default:
+		return date.AddDate(0, 0, r.Amount)
+	}
+}
+
+// addMonths adds a number of months to a date, moving days that do not exist in
+// the target month to its last day, so that 2024-01-31 plus 1 month is 2024-02-29
+// rather than 2024-03-02
+func addMonths(date time.Time, months int) time.Time {
+	year, month, day := date.Date()
+	lastDay := time.Date(year, month+time.Month(months)+1, 0, 0, 0, 0, 0, date.Location()).Day()
+	return time.Date(year, month+time.Month(months), min(day, lastDay),
+		date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+}
+
+// Recurrence returns the recurrence set by the rec: tag
+func (t Todo) Recurrence() (Recurrence, bool) {
+	value, ok := t.GetTag("rec")
+	if !ok {
+		return Recurrence{}, false
+	}
+	return ParseRecurrence(value)
+}
+
+// NextRecurrence returns the next occurrence of a recurring todo completed on
+// the given date: a pending copy with its due date advanced, and its threshold
+// date moved so that it keeps the same distance from the due date. Todos with
+// neither date get a due date. A creation date is replaced by the completion date.
+func (t Todo) NextRecurrence(completedAt time.Time) (Todo, bool) {
+	recurrence, ok := t.Recurrence()
+	if !ok {
+		return Todo{}, false
+	}
+
+	next := NewTodo(t.Text)
+	next.Reopen(false)
+
+	due, hasDue := next.Due()
+	threshold, hasThreshold := next.Threshold()
+
+	base := completedAt
+	if recurrence.Strict && hasDue {
+		base = due
+	} else if recurrence.Strict && hasThreshold {
+		base = threshold
+	}
+
+	switch {
+	case hasDue:
+		nextDue := recurrence.Next(base)
+		next.SetTag("due", nextDue.Format(DateLayout))
+		if hasThreshold {
+			gap := daysBetween(threshold, due)
+			next.SetTag("t", nextDue.AddDate(0, 0, -gap).Format(DateLayout))
+		}
+	case hasThreshold:
+		next.SetTag("t", recurrence.Next(base).Format(DateLayout))
+	default:
+		next.SetTag("due", recurrence.Next(base).Format(DateLayout))
+	}
+
+	if !next.CreatedAt.IsZero() {
+		next.SetCreatedAt(completedAt)
+	}
+
+	return next, true
+}
@@ -0,0 +1,188 @@
+package todotxtlib
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   Recurrence
+		wantOk bool
+	}{
+		{
+			name:   "weekly",
+			value:  "1w",
+			want:   Recurrence{Amount: 1, Unit: 'w'},
+			wantOk: true,
+		},
+		{
+			name:   "strict monthly",
+			value:  "+2m",
+			want:   Recurrence{Strict: true, Amount: 2, Unit: 'm'},
+			wantOk: true,
+		},
+		{
+			name:   "amount defaults to one",
+			value:  "y",
+			want:   Recurrence{Amount: 1, Unit: 'y'},
+			wantOk: true,
+		},
+		{
+			name:   "zero amount",
+			value:  "0d",
+			wantOk: false,
+		},
+		{
+			name:   "unknown unit",
+			value:  "3q",
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseRecurrence(tt.value)
+			if ok != tt.wantOk {
+				t.Fatalf("ParseRecurrence() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRecurrence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrence_Next(t *testing.T) {
+	friday := time.Date(2024, 3, 8, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "days", value: "3d", want: "2024-03-11"},
+		{name: "weeks", value: "2w", want: "2024-03-22"},
+		{name: "months", value: "1m", want: "2024-04-08"},
+		{name: "years", value: "1y", want: "2025-03-08"},
+		{name: "business days skip weekend", value: "1b", want: "2024-03-11"},
+		{name: "several business days", value: "6b", want: "2024-03-18"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurrence, ok := ParseRecurrence(tt.value)
+			if !ok {
+				t.Fatalf("ParseRecurrence(%q) failed", tt.value)
+			}
+			if got := recurrence.Next(friday).Format(DateLayout); got != tt.want {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrence_Next_MonthEnd(t *testing.T) {
+	tests := []struct {
+		date  string
+		value string
+		want  string
+	}{
+		{date: "2024-01-31", value: "1m", want: "2024-02-29"},
+		{date: "2023-01-31", value: "1m", want: "2023-02-28"},
+		{date: "2024-03-31", value: "1m", want: "2024-04-30"},
+		{date: "2024-10-31", value: "4m", want: "2025-02-28"},
+		{date: "2024-12-31", value: "2m", want: "2025-02-28"},
+		{date: "2024-02-29", value: "1y", want: "2025-02-28"},
+		{date: "2024-02-29", value: "4y", want: "2028-02-29"},
+	}
+
+	for _, tt := range tests {
+		date, err := time.ParseInLocation(DateLayout, tt.date, time.Local)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", tt.date, err)
+		}
+		recurrence, _ := ParseRecurrence(tt.value)
+		if got := recurrence.Next(date).Format(DateLayout); got != tt.want {
+			t.Errorf("Next(%s) with rec:%s = %v, want %v", tt.date, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestTodo_NextRecurrence(t *testing.T) {
+	completedAt := time.Date(2024, 3, 10, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name   string
+		text   string
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "from completion date",
+			text:   "(A) Water plants due:2024-03-05 rec:1w",
+			want:   "(A) Water plants due:2024-03-17 rec:1w",
+			wantOk: true,
+		},
+		{
+			name:   "strict from due date",
+			text:   "Pay rent due:2024-03-01 rec:+1m",
+			want:   "Pay rent due:2024-04-01 rec:+1m",
+			wantOk: true,
+		},
+		{
+			name:   "strict monthly keeps to the end of the month",
+			text:   "Close books due:2024-01-31 rec:+1m",
+			want:   "Close books due:2024-02-29 rec:+1m",
+			wantOk: true,
+		},
+		{
+			name:   "threshold keeps distance to due date",
+			text:   "Pay rent t:2024-02-25 due:2024-03-01 rec:+1m",
+			want:   "Pay rent t:2024-03-27 due:2024-04-01 rec:+1m",
+			wantOk: true,
+		},
+		{
+			name:   "threshold only",
+			text:   "Review goals t:2024-03-01 rec:+2w",
+			want:   "Review goals t:2024-03-15 rec:+2w",
+			wantOk: true,
+		},
+		{
+			name:   "no dates adds due date",
+			text:   "Stretch rec:2d",
+			want:   "Stretch rec:2d due:2024-03-12",
+			wantOk: true,
+		},
+		{
+			name:   "creation date is replaced",
+			text:   "2024-01-01 Stretch due:2024-03-10 rec:1d",
+			want:   "2024-03-10 Stretch due:2024-03-11 rec:1d",
+			wantOk: true,
+		},
+		{
+			name:   "not recurring",
+			text:   "Stretch due:2024-03-10",
+			wantOk: false,
+		},
+		{
+			name:   "invalid recurrence",
+			text:   "Stretch rec:often",
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NewTodo(tt.text).NextRecurrence(completedAt)
+			if ok != tt.wantOk {
+				t.Fatalf("NextRecurrence() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if got.Text != tt.want {
+				t.Errorf("NextRecurrence() Text = %v, want %v", got.Text, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
+	"time"
 )
 
 // TodoRepository defines the interface for storing and manipulating Todos.
@@ -29,33 +30,135 @@ type TodoRepository interface {
 	ListProjects() ([]string, error)
 	ListContexts() ([]string, error)
 	Save() error
+	Reload() error
 	WriteToString() (string, error)
 }
 
 // FileRepository handles storing and manipulating Todos in a file.
 type FileRepository struct {
-	todos  []Todo
-	reader Reader
-	writer Writer
+	todos   []Todo
+	reader  Reader
+	writer  Writer
+	base    []string // lines as last loaded or saved, used to merge changes made by other programs
+	version Version  // version of the file as last loaded or saved
+
+	lockPath    string        // lock file held while reading and saving, if set
+	lockTimeout time.Duration // how long to wait for the lock
+	held        *fileLock     // the lock while it is held
+	holds       int           // number of unreleased calls to lock
 }
 
-// NewFileRepository creates a new repository with custom reader and writer
-func NewFileRepository(reader Reader, writer Writer) (TodoRepository, error) {
-	todos, err := reader.Read()
-	if err != nil {
-		return nil, err
+// RepositoryOption configures optional behaviour of a FileRepository
+type RepositoryOption func(*FileRepository)
+
+// WithLocking takes an advisory lock on path.lock while the todo.txt file at path
+// is read and while it is saved, so that other togodo processes cannot write it
+// at the same time. To keep other processes from changing the file between
+// reading and saving it, hold the lock across both with Lock; otherwise Save
+// merges changes made in between, see Save. Waiting for the lock fails with
+// ErrLockTimeout after timeout.
+func WithLocking(path string, timeout time.Duration) RepositoryOption {
+	return func(r *FileRepository) {
+		if resolved, err := resolveSymlinks(path); err == nil {
+			path = resolved
+		}
+		r.lockPath = path + ".lock"
+		r.lockTimeout = timeout
 	}
+}
 
-	return &FileRepository{
-		todos:  todos,
+// NewFileRepository creates a new repository with custom reader, writer and options.
+// If the reader is a VersionedReader, Save merges changes made to the file by
+// other programs since it was loaded.
+func NewFileRepository(reader Reader, writer Writer, opts ...RepositoryOption) (TodoRepository, error) {
+	repo := &FileRepository{
 		reader: reader,
 		writer: writer,
+	}
+	for _, opt := range opts {
+		opt(repo)
+	}
+
+	unlock, err := repo.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var todos []Todo
+	var version Version
+	if versioned, ok := reader.(VersionedReader); ok {
+		todos, version, err = versioned.ReadVersion()
+	} else {
+		todos, err = reader.Read()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	repo.todos = todos
+	repo.base = todoLines(todos)
+	repo.version = version
+	repo.renumber()
+	return repo, nil
+}
+
+// Lock holds the lock configured WithLocking, if any, until the returned function
+// is called. Reads and saves while it is held use it rather than waiting for it
+func (r *FileRepository) Lock() (func(), error) {
+	return r.lock()
+}
+
+// lock acquires the lock configured WithLocking, if any, and returns a function that releases it
+// The lock is only released once every function returned while it was held has been called
+func (r *FileRepository) lock() (func(), error) {
+	if r.lockPath == "" {
+		return func() {}, nil
+	}
+
+	if r.holds == 0 {
+		lock, err := acquireLock(r.lockPath, r.lockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		r.held = lock
+	}
+	r.holds++
+
+	released := false
+	return func() {
+		if released {
+			return
+		}
+		released = true
+		r.holds--
+		if r.holds == 0 {
+			r.held.release()
+			r.held = nil
+		}
 	}, nil
 }
 
+// todoLines returns the text of each todo
+func todoLines(todos []Todo) []string {
+	lines := make([]string, len(todos))
+	for i, todo := range todos {
+		lines[i] = todo.Text
+	}
+	return lines
+}
+
+// renumber sets the line number of each todo to its position in the repository
+func (r *FileRepository) renumber() {
+	for i := range r.todos {
+		r.todos[i].LineNumber = i + 1
+	}
+}
+
 // Add adds a todo to the repository
 func (r *FileRepository) Add(todoText string) (Todo, error) {
 	newTodo := NewTodo(todoText)
+	newTodo.LineNumber = len(r.todos) + 1
 	r.todos = append(r.todos, newTodo)
 	return newTodo, nil
 }
@@ -67,6 +170,7 @@ func (r *FileRepository) Remove(index int) (Todo, error) {
 	}
 	todo := r.todos[index]
 	r.todos = append(r.todos[:index], r.todos[index+1:]...)
+	r.renumber()
 	return todo, nil
 }
 
@@ -75,6 +179,7 @@ func (r *FileRepository) Update(index int, todo Todo) (Todo, error) {
 	if index < 0 || index >= len(r.todos) {
 		return Todo{}, fmt.Errorf("index out of bounds")
 	}
+	todo.LineNumber = index + 1
 	r.todos[index] = todo
 	return todo, nil
 }
@@ -170,12 +275,14 @@ func (r FileRepository) Search(query string) ([]Todo, error) {
 // Sort sorts the todos in the repository according to the specified criteria
 func (r *FileRepository) Sort(sort Sort) {
 	sort.Apply(r.todos)
+	r.renumber()
 }
 
 // SortDefault sorts the todos in the repository in default order, with done todos at the bottom.
 func (r *FileRepository) SortDefault() {
 	sort := NewDefaultSort()
 	sort.Apply(r.todos)
+	r.renumber()
 }
 
 // ListAll returns all todos
@@ -183,11 +290,12 @@ func (r FileRepository) ListAll() ([]Todo, error) {
 	return r.todos, nil
 }
 
-// ListTodos returns all todos that are not done
+// ListTodos returns all todos that are not done and not hidden by a future threshold date
 func (r FileRepository) ListTodos() ([]Todo, error) {
 	notDone := []Todo{}
+	today := time.Now()
 	for _, todo := range r.todos {
-		if !todo.Done {
+		if !todo.Done && todo.Actionable(today) {
 			notDone = append(notDone, todo)
 		}
 	}
@@ -241,9 +349,71 @@ func (r FileRepository) ListContexts() ([]string, error) {
 	return contexts, nil
 }
 
-// Save saves the todos using the configured writer
+// Save saves the todos using the configured writer. If the file was changed by
+// another program since it was loaded, those changes are merged in first, or a
+// *ConflictError is returned without saving if they conflict.
 func (r *FileRepository) Save() error {
-	return r.writer.Write(r.todos)
+	unlock, err := r.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := r.mergeExternalChanges(); err != nil {
+		return err
+	}
+
+	if err := r.writer.Write(r.todos); err != nil {
+		return err
+	}
+
+	r.base = todoLines(r.todos)
+	r.version = versionOf(formatTodos(r.todos))
+	return nil
+}
+
+// Reload reads the file again to pick up changes made by other programs, keeping
+// any unsaved changes. If they conflict, a *ConflictError is returned and nothing
+// changes. Reload does nothing if the reader is not a VersionedReader.
+func (r *FileRepository) Reload() error {
+	unlock, err := r.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return r.mergeExternalChanges()
+}
+
+// mergeExternalChanges merges changes made to the file by other programs since
+// it was last loaded or saved into the todos, which then count as loaded
+func (r *FileRepository) mergeExternalChanges() error {
+	reader, ok := r.reader.(VersionedReader)
+	if !ok {
+		return nil
+	}
+
+	theirs, version, err := reader.ReadVersion()
+	if err != nil {
+		return err
+	}
+	if version == r.version {
+		return nil
+	}
+
+	merged, conflicts := mergeLines(r.base, todoLines(r.todos), todoLines(theirs))
+	if len(conflicts) > 0 {
+		return &ConflictError{Lines: conflicts}
+	}
+
+	r.todos = make([]Todo, len(merged))
+	for i, line := range merged {
+		r.todos[i] = NewTodo(line)
+	}
+	r.renumber()
+	r.base = todoLines(theirs)
+	r.version = version
+	return nil
 }
 
 // WriteToString returns the todos as a string representation
@@ -2,6 +2,7 @@ package todotxtlib
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
 	"slices"
@@ -393,9 +394,8 @@ func TestRepository_Filter(t *testing.T) {
 	t.Run("filters todos with a combined filter", func(t *testing.T) {
 		filter := Filter{
 			Done:     "true",
-			Priority: "",
-			Project:  "+project1",
-			Context:  "@context1",
+			Projects: Set{All: []string{"+project1"}},
+			Contexts: Set{All: []string{"@context1"}},
 		}
 		filtered, err := repo.Filter(filter)
 		if err != nil {
@@ -491,6 +491,62 @@ func TestRepository_Sort(t *testing.T) {
 	}
 }
 
+func TestRepository_LineNumbers(t *testing.T) {
+	lineNumbers := func(todos []Todo) []int {
+		numbers := make([]int, len(todos))
+		for i, todo := range todos {
+			numbers[i] = todo.LineNumber
+		}
+		return numbers
+	}
+
+	t.Run("numbers todos read from the file", func(t *testing.T) {
+		repo, _ := setupTestRepository(t)
+		todos, _ := repo.ListAll()
+		if got := lineNumbers(todos); !slices.Equal(got, []int{1, 2, 3}) {
+			t.Errorf("ListAll() line numbers = %v, want [1 2 3]", got)
+		}
+	})
+
+	t.Run("numbers added todos", func(t *testing.T) {
+		repo, _ := setupTestRepository(t)
+		todo, _ := repo.Add("new todo")
+		if todo.LineNumber != 4 {
+			t.Errorf("Add() line number = %d, want 4", todo.LineNumber)
+		}
+	})
+
+	t.Run("renumbers after removing a todo", func(t *testing.T) {
+		repo, _ := setupTestRepository(t)
+		repo.Remove(0)
+		todos, _ := repo.ListAll()
+		if got := lineNumbers(todos); !slices.Equal(got, []int{1, 2}) {
+			t.Errorf("ListAll() line numbers = %v, want [1 2]", got)
+		}
+	})
+
+	t.Run("renumbers after sorting", func(t *testing.T) {
+		repo, _ := setupTestRepository(t)
+		repo.Sort(Sort{Field: Text, Order: Descending})
+		todos, _ := repo.ListAll()
+		if got := lineNumbers(todos); !slices.Equal(got, []int{1, 2, 3}) {
+			t.Errorf("ListAll() line numbers = %v, want [1 2 3]", got)
+		}
+		if todos[0].Text != "x (C) test todo 3 +project1 @context1" {
+			t.Errorf("ListAll() first todo = %q, want the done todo", todos[0].Text)
+		}
+	})
+
+	t.Run("keeps line numbers when filtering and sorting results", func(t *testing.T) {
+		repo, _ := setupTestRepository(t)
+		filtered, _ := repo.Filter(Filter{Projects: Set{All: []string{"+project1"}}})
+		Sort{Keys: []SortKey{{Field: Text, Order: Descending}}}.Apply(filtered)
+		if got := lineNumbers(filtered); !slices.Equal(got, []int{3, 2}) {
+			t.Errorf("filtered line numbers = %v, want [3 2]", got)
+		}
+	})
+}
+
 func TestRepository_ListTodos(t *testing.T) {
 	repo, _ := setupTestRepository(t)
 
@@ -503,6 +559,20 @@ func TestRepository_ListTodos(t *testing.T) {
 			t.Errorf("ListTodos() returned %d todos, want 2", len(todos))
 		}
 	})
+
+	t.Run("hides todos with a future threshold date", func(t *testing.T) {
+		repo, _ := setupEmptyTestRepository(t)
+		repo.Add("parked task t:2999-01-01")
+		repo.Add("current task t:2000-01-01")
+
+		todos, err := repo.ListTodos()
+		if err != nil {
+			t.Errorf("ListTodos() error = %v, want nil", err)
+		}
+		if len(todos) != 1 || todos[0].Text != "current task t:2000-01-01" {
+			t.Errorf("ListTodos() = %v, want only the current task", todos)
+		}
+	})
 }
 
 func TestRepository_ListDone(t *testing.T) {
@@ -581,3 +651,157 @@ func TestRepository_Save(t *testing.T) {
 		}
 	})
 }
+
+func TestRepository_SaveExternalChanges(t *testing.T) {
+	setup := func(t *testing.T) (TodoRepository, string) {
+		path := filepath.Join(t.TempDir(), "todo.txt")
+		if err := os.WriteFile(path, []byte("(A) task one\ntask two\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		repo, err := NewFileRepository(NewFileReader(path), NewFileWriter(path))
+		if err != nil {
+			t.Fatalf("NewFileRepository() error = %v", err)
+		}
+		return repo, path
+	}
+
+	readFile := func(t *testing.T, path string) string {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read test file: %v", err)
+		}
+		return string(content)
+	}
+
+	t.Run("saves without external changes", func(t *testing.T) {
+		repo, path := setup(t)
+		repo.Add("task three")
+
+		if err := repo.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if err := repo.Save(); err != nil {
+			t.Fatalf("second Save() error = %v", err)
+		}
+
+		if got, want := readFile(t, path), "(A) task one\ntask two\ntask three\n"; got != want {
+			t.Errorf("file content = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("merges external changes", func(t *testing.T) {
+		repo, path := setup(t)
+		repo.SetPriority(1, "B")
+		if err := os.WriteFile(path, []byte("(A) task one\ntask two\nexternal task\n"), 0644); err != nil {
+			t.Fatalf("Failed to modify test file: %v", err)
+		}
+
+		if err := repo.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		want := "(A) task one\n(B) task two\nexternal task\n"
+		if got := readFile(t, path); got != want {
+			t.Errorf("file content = %q, want %q", got, want)
+		}
+		todos, _ := repo.ListAll()
+		if len(todos) != 3 || todos[2].Text != "external task" || todos[2].LineNumber != 3 {
+			t.Errorf("ListAll() = %v, want the merged todos", todos)
+		}
+	})
+
+	t.Run("returns a conflict error for conflicting changes", func(t *testing.T) {
+		repo, path := setup(t)
+		repo.SetPriority(1, "B")
+		external := "(A) task one\n(C) task two\n"
+		if err := os.WriteFile(path, []byte(external), 0644); err != nil {
+			t.Fatalf("Failed to modify test file: %v", err)
+		}
+
+		err := repo.Save()
+
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) {
+			t.Fatalf("Save() error = %v, want *ConflictError", err)
+		}
+		if !slices.Equal(conflict.Lines, []string{"task two"}) {
+			t.Errorf("ConflictError.Lines = %q, want [\"task two\"]", conflict.Lines)
+		}
+		if got := readFile(t, path); got != external {
+			t.Errorf("file content = %q, want it unchanged", got)
+		}
+	})
+}
+
+func TestRepository_Reload(t *testing.T) {
+	setup := func(t *testing.T) (TodoRepository, string) {
+		path := filepath.Join(t.TempDir(), "todo.txt")
+		if err := os.WriteFile(path, []byte("task one\ntask two\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		repo, err := NewFileRepository(NewFileReader(path), NewFileWriter(path))
+		if err != nil {
+			t.Fatalf("NewFileRepository() error = %v", err)
+		}
+		return repo, path
+	}
+
+	t.Run("picks up external changes and keeps unsaved ones", func(t *testing.T) {
+		repo, path := setup(t)
+		repo.SetPriority(0, "A")
+		if err := os.WriteFile(path, []byte("task one\ntask two\nexternal task\n"), 0644); err != nil {
+			t.Fatalf("Failed to modify test file: %v", err)
+		}
+
+		if err := repo.Reload(); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+
+		todos, _ := repo.ListAll()
+		got := todoLines(todos)
+		if want := []string{"(A) task one", "task two", "external task"}; !slices.Equal(got, want) {
+			t.Errorf("ListAll() = %q, want %q", got, want)
+		}
+
+		// The unsaved change is saved against the reloaded file without conflicts
+		if err := repo.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		content, _ := os.ReadFile(path)
+		if want := "(A) task one\ntask two\nexternal task\n"; string(content) != want {
+			t.Errorf("file content = %q, want %q", string(content), want)
+		}
+	})
+
+	t.Run("does nothing if the file has not changed", func(t *testing.T) {
+		repo, _ := setup(t)
+		repo.Add("unsaved task")
+
+		if err := repo.Reload(); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+
+		todos, _ := repo.ListAll()
+		if len(todos) != 3 {
+			t.Errorf("ListAll() returned %d todos, want 3", len(todos))
+		}
+	})
+
+	t.Run("returns a conflict error for conflicting changes", func(t *testing.T) {
+		repo, path := setup(t)
+		repo.SetPriority(0, "A")
+		if err := os.WriteFile(path, []byte("(B) task one\ntask two\n"), 0644); err != nil {
+			t.Fatalf("Failed to modify test file: %v", err)
+		}
+
+		var conflict *ConflictError
+		if err := repo.Reload(); !errors.As(err, &conflict) {
+			t.Fatalf("Reload() error = %v, want *ConflictError", err)
+		}
+
+		todos, _ := repo.ListAll()
+		if todos[0].Text != "(A) task one" {
+			t.Errorf("ListAll() first todo = %q, want the unsaved change to be kept", todos[0].Text)
+		}
+	})
+}
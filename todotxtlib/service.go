@@ -1,32 +1,144 @@
 package todotxtlib
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
 
 // TodoService provides high-level operations for managing todos
 type TodoService interface {
 	AddTodos(texts []string) ([]Todo, error)
+	AddDatedTodos(texts []string) ([]Todo, error)
 	ToggleTodos(indices []int) ([]Todo, error)
 	SetPriorities(indices []int, priority string) ([]Todo, error)
+	DeprioritizeTodos(indices []int) ([]Todo, error)
+	ReopenTodos(indices []int) ([]Todo, error)
+	RemoveTodos(indices []int) ([]Todo, error)
+	ReplaceTodo(index int, text string) (Todo, error)
+	AppendToTodo(index int, text string) (Todo, error)
+	PrependToTodo(index int, text string) (Todo, error)
+	RenameProject(from, to string) ([]Todo, error)
+	MergeProjects(from []string, to string) ([]Todo, error)
+	RenameContext(from, to string) ([]Todo, error)
+	MergeContexts(from []string, to string) ([]Todo, error)
+	TagTodos(indices []int, tags []string) ([]Todo, error)
+	UntagTodos(indices []int, tags []string) ([]Todo, error)
 	RemoveDoneTodos() ([]Todo, error)
+	ArchiveDoneTodos() ([]Todo, error)
 	SearchTodos(query string) ([]Todo, error)
+	FilterTodos(filter Filter) ([]Todo, error)
+	DueTodos(days int) ([]Todo, error)
+	Undo() (JournalEntry, error)
+	Redo() (JournalEntry, error)
+	Save() error
+	Reload() error
+	Dirty() bool
+	Lock() (func(), error)
 }
 
 // DefaultTodoService implements TodoService using a TodoRepository
 type DefaultTodoService struct {
-	repo TodoRepository
+	repo        TodoRepository
+	now         func() time.Time
+	priorityTag bool
+	dateOnAdd   bool
+	archive     Writer
+	manualSave  bool
+	dirty       bool
+	journal     Journal
+	unsaved     []journalChange // changes to the journal waiting for the todos to be saved
 }
 
-// NewTodoService creates a new TodoService with the given repository
-func NewTodoService(repo TodoRepository) TodoService {
-	return &DefaultTodoService{repo: repo}
+// ServiceOption configures optional behaviour of a DefaultTodoService
+type ServiceOption func(*DefaultTodoService)
+
+// WithClock sets the function used to get the current time, e.g. for completion dates
+func WithClock(now func() time.Time) ServiceOption {
+	return func(s *DefaultTodoService) {
+		s.now = now
+	}
+}
+
+// WithPriorityTag keeps the priority of completed todos in a pri: tag,
+// so that it is restored when the todo is reopened
+func WithPriorityTag(enabled bool) ServiceOption {
+	return func(s *DefaultTodoService) {
+		s.priorityTag = enabled
+	}
+}
+
+// WithDateOnAdd prepends the current date to todos added with AddTodos
+func WithDateOnAdd(enabled bool) ServiceOption {
+	return func(s *DefaultTodoService) {
+		s.dateOnAdd = enabled
+	}
+}
+
+// WithArchive sets the writer that ArchiveDoneTodos appends completed todos to, e.g. done.txt
+func WithArchive(writer Writer) ServiceOption {
+	return func(s *DefaultTodoService) {
+		s.archive = writer
+	}
+}
+
+// WithAutoSave controls whether changes are saved as soon as they are made, which is
+// the default. When disabled, changes are kept in memory until Save is called
+func WithAutoSave(enabled bool) ServiceOption {
+	return func(s *DefaultTodoService) {
+		s.manualSave = !enabled
+	}
+}
+
+// WithJournal records every change to the todos in the journal, so that it can be undone and redone
+func WithJournal(journal Journal) ServiceOption {
+	return func(s *DefaultTodoService) {
+		s.journal = journal
+	}
+}
+
+// NewTodoService creates a new TodoService with the given repository and options
+func NewTodoService(repo TodoRepository, opts ...ServiceOption) TodoService {
+	service := &DefaultTodoService{
+		repo: repo,
+		now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(service)
+	}
+	return service
 }
 
 // AddTodos adds multiple todos, sorts the list, and saves
+// Todos are stamped with a creation date if the service was created WithDateOnAdd
 // Returns the added todos
 func (s *DefaultTodoService) AddTodos(texts []string) ([]Todo, error) {
+	return s.addTodos(texts, s.dateOnAdd)
+}
+
+// AddDatedTodos adds multiple todos stamped with the current date as their
+// creation date, regardless of the WithDateOnAdd setting
+// Returns the added todos
+func (s *DefaultTodoService) AddDatedTodos(texts []string) ([]Todo, error) {
+	return s.addTodos(texts, true)
+}
+
+// addTodos adds multiple todos, optionally stamping them with a creation date
+func (s *DefaultTodoService) addTodos(texts []string, dated bool) ([]Todo, error) {
+	before := s.snapshot()
 	addedTodos := make([]Todo, 0, len(texts))
 
 	for _, text := range texts {
+		if dated {
+			newTodo := NewTodo(text)
+			if newTodo.CreatedAt.IsZero() {
+				newTodo.SetCreatedAt(s.now())
+			}
+			text = newTodo.Text
+		}
+
 		todo, err := s.repo.Add(text)
 		if err != nil {
 			return nil, fmt.Errorf("failed to add todo: %w", err)
@@ -35,38 +147,80 @@ func (s *DefaultTodoService) AddTodos(texts []string) ([]Todo, error) {
 	}
 
 	s.repo.SortDefault()
-	if err := s.repo.Save(); err != nil {
-		return nil, fmt.Errorf("failed to save todos: %w", err)
+	s.record("add todos", before)
+	if err := s.changed(); err != nil {
+		return nil, err
 	}
 
 	return addedTodos, nil
 }
 
 // ToggleTodos toggles the done status of todos at the given indices (0-based)
-// Returns the toggled todos
+// Completed todos are stamped with the current date, reopened todos have it removed
+// Completing a todo with a rec: tag adds its next occurrence to the list
+// Returns the toggled todos, followed by any new occurrences of recurring todos
 func (s *DefaultTodoService) ToggleTodos(indices []int) ([]Todo, error) {
+	if index, ok := s.outOfBounds(indices); ok {
+		return nil, fmt.Errorf("failed to toggle todo at index %d: index out of bounds", index)
+	}
+
+	before := s.snapshot()
 	toggledTodos := make([]Todo, 0, len(indices))
+	recurringTodos := []Todo{}
 
 	for _, index := range indices {
-		todo, err := s.repo.ToggleDone(index)
+		allTodos, err := s.repo.ListAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list all todos: %w", err)
+		}
+
+		todo := allTodos[index]
+		if todo.Done {
+			todo.Reopen(s.priorityTag)
+		} else {
+			now := s.now()
+			if next, ok := todo.NextRecurrence(now); ok {
+				recurringTodo, err := s.repo.Add(next.Text)
+				if err != nil {
+					return nil, fmt.Errorf("failed to add next occurrence of todo at index %d: %w", index, err)
+				}
+				recurringTodos = append(recurringTodos, recurringTodo)
+			}
+			todo.Complete(now, s.priorityTag)
+		}
+
+		todo, err = s.repo.Update(index, todo)
 		if err != nil {
 			return nil, fmt.Errorf("failed to toggle todo at index %d: %w", index, err)
 		}
 		toggledTodos = append(toggledTodos, todo)
 	}
+	toggledTodos = append(toggledTodos, recurringTodos...)
 
 	s.repo.SortDefault()
-	if err := s.repo.Save(); err != nil {
-		return nil, fmt.Errorf("failed to save todos: %w", err)
+	s.record("toggle todos", before)
+	if err := s.changed(); err != nil {
+		return nil, err
 	}
 
 	return toggledTodos, nil
 }
 
-// SetPriorities sets the priority for todos at the given indices (0-based)
+// SetPriorities sets the priority for todos at the given indices (0-based). The
+// priority must be a single letter, which is upper cased, or empty to remove it,
+// see ParsePriority
 // Returns the updated todos
 // Note: Does not sort after setting priorities to preserve user's intended order
 func (s *DefaultTodoService) SetPriorities(indices []int, priority string) ([]Todo, error) {
+	priority, err := ParsePriority(priority)
+	if err != nil {
+		return nil, err
+	}
+	if index, ok := s.outOfBounds(indices); ok {
+		return nil, fmt.Errorf("failed to set priority for todo at index %d: index out of bounds", index)
+	}
+
+	before := s.snapshot()
 	updatedTodos := make([]Todo, 0, len(indices))
 
 	for _, index := range indices {
@@ -78,16 +232,681 @@ func (s *DefaultTodoService) SetPriorities(indices []int, priority string) ([]To
 	}
 
 	// Note: Pri command doesn't sort - preserves user's order
-	if err := s.repo.Save(); err != nil {
-		return nil, fmt.Errorf("failed to save todos: %w", err)
+	s.record("set priorities", before)
+	if err := s.changed(); err != nil {
+		return nil, err
+	}
+
+	return updatedTodos, nil
+}
+
+// DeprioritizeTodos removes the priority of todos at the given indices (0-based)
+// Like SetPriorities, the list is not sorted afterwards
+// Returns the updated todos
+func (s *DefaultTodoService) DeprioritizeTodos(indices []int) ([]Todo, error) {
+	if err := s.checkIndices(indices); err != nil {
+		return nil, fmt.Errorf("failed to remove priorities: %w", err)
+	}
+
+	before := s.snapshot()
+	updatedTodos := make([]Todo, 0, len(indices))
+
+	for _, index := range indices {
+		todo, err := s.repo.SetPriority(index, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove priority of todo at index %d: %w", index, err)
+		}
+		updatedTodos = append(updatedTodos, todo)
+	}
+
+	s.record("remove priorities", before)
+	if err := s.changed(); err != nil {
+		return nil, err
+	}
+
+	return updatedTodos, nil
+}
+
+// ReopenTodos marks todos at the given indices (0-based) as not done, removing their
+// completion date. Unlike ToggleTodos, todos that are not done are left unchanged
+// Returns the reopened todos
+func (s *DefaultTodoService) ReopenTodos(indices []int) ([]Todo, error) {
+	if err := s.checkIndices(indices); err != nil {
+		return nil, fmt.Errorf("failed to reopen todos: %w", err)
+	}
+	allTodos, err := s.repo.ListAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list all todos: %w", err)
+	}
+
+	before := s.snapshot()
+	reopenedTodos := make([]Todo, 0, len(indices))
+
+	for _, index := range indices {
+		todo := allTodos[index]
+		todo.Reopen(s.priorityTag)
+
+		todo, err := s.repo.Update(index, todo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reopen todo at index %d: %w", index, err)
+		}
+		reopenedTodos = append(reopenedTodos, todo)
+	}
+
+	s.repo.SortDefault()
+	s.record("reopen todos", before)
+	if err := s.changed(); err != nil {
+		return nil, err
+	}
+
+	return reopenedTodos, nil
+}
+
+// RemoveTodos deletes the todos at the given indices (0-based). Nothing is removed
+// if any of the indices is out of bounds
+// Returns the removed todos, in the order of the list
+func (s *DefaultTodoService) RemoveTodos(indices []int) ([]Todo, error) {
+	if err := s.checkIndices(indices); err != nil {
+		return nil, fmt.Errorf("failed to remove todos: %w", err)
+	}
+
+	before := s.snapshot()
+	indices = slices.Compact(slices.Sorted(slices.Values(indices)))
+	removedTodos := make([]Todo, len(indices))
+
+	// Remove backwards to avoid index shifting
+	for i := len(indices) - 1; i >= 0; i-- {
+		todo, err := s.repo.Remove(indices[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove todo at index %d: %w", indices[i], err)
+		}
+		removedTodos[i] = todo
+	}
+
+	s.record("remove todos", before)
+	if err := s.changed(); err != nil {
+		return nil, err
+	}
+
+	return removedTodos, nil
+}
+
+// ReplaceTodo replaces the todo at the given index (0-based) with a new todo
+// Returns the new todo
+func (s *DefaultTodoService) ReplaceTodo(index int, text string) (Todo, error) {
+	return s.editTodo("replace todo", index, func(todo *Todo) {
+		*todo = NewTodo(text)
+	})
+}
+
+// AppendToTodo adds text to the end of the todo at the given index (0-based)
+// Returns the updated todo
+func (s *DefaultTodoService) AppendToTodo(index int, text string) (Todo, error) {
+	return s.editTodo("append to todo", index, func(todo *Todo) {
+		todo.Append(text)
+	})
+}
+
+// PrependToTodo adds text to the start of the todo at the given index (0-based),
+// after its done marker, priority and dates
+// Returns the updated todo
+func (s *DefaultTodoService) PrependToTodo(index int, text string) (Todo, error) {
+	return s.editTodo("prepend to todo", index, func(todo *Todo) {
+		todo.Prepend(text)
+	})
+}
+
+// editTodo changes the text of the todo at the given index (0-based) and saves
+// Like SetPriorities, the list is not sorted afterwards so the todo keeps its line number
+func (s *DefaultTodoService) editTodo(operation string, index int, edit func(todo *Todo)) (Todo, error) {
+	if err := s.checkIndices([]int{index}); err != nil {
+		return Todo{}, fmt.Errorf("failed to %s: %w", operation, err)
+	}
+	allTodos, err := s.repo.ListAll()
+	if err != nil {
+		return Todo{}, fmt.Errorf("failed to list all todos: %w", err)
+	}
+
+	before := s.snapshot()
+	todo := allTodos[index]
+	edit(&todo)
+
+	todo, err = s.repo.Update(index, todo)
+	if err != nil {
+		return Todo{}, fmt.Errorf("failed to %s at index %d: %w", operation, index, err)
+	}
+
+	s.record(operation, before)
+	if err := s.changed(); err != nil {
+		return Todo{}, err
+	}
+
+	return todo, nil
+}
+
+// itemKind describes how to edit either the projects or the contexts of todos
+type itemKind struct {
+	name   string
+	re     *regexp.Regexp
+	items  func(todo Todo) []string
+	add    func(repo TodoRepository, index int, item string) (Todo, error)
+	remove func(repo TodoRepository, index int, item string) (Todo, error)
+}
+
+var (
+	projectKind = itemKind{
+		name:   "project",
+		re:     projectRe,
+		items:  func(todo Todo) []string { return todo.Projects },
+		add:    TodoRepository.AddProject,
+		remove: TodoRepository.RemoveProject,
+	}
+	contextKind = itemKind{
+		name:   "context",
+		re:     contextRe,
+		items:  func(todo Todo) []string { return todo.Contexts },
+		add:    TodoRepository.AddContext,
+		remove: TodoRepository.RemoveContext,
+	}
+)
+
+// RenameProject renames a project, e.g. +old to +new, on every todo in it
+// Fails if any todo is already in the new project, use MergeProjects to combine projects
+// Returns the updated todos
+func (s *DefaultTodoService) RenameProject(from, to string) ([]Todo, error) {
+	return s.replaceItems(projectKind, []string{from}, to, false)
+}
+
+// MergeProjects moves every todo in any of the from projects to the to project
+// Returns the updated todos
+func (s *DefaultTodoService) MergeProjects(from []string, to string) ([]Todo, error) {
+	return s.replaceItems(projectKind, from, to, true)
+}
+
+// RenameContext renames a context, e.g. @old to @new, on every todo in it
+// Fails if any todo is already in the new context, use MergeContexts to combine contexts
+// Returns the updated todos
+func (s *DefaultTodoService) RenameContext(from, to string) ([]Todo, error) {
+	return s.replaceItems(contextKind, []string{from}, to, false)
+}
+
+// MergeContexts moves every todo in any of the from contexts to the to context
+// Returns the updated todos
+func (s *DefaultTodoService) MergeContexts(from []string, to string) ([]Todo, error) {
+	return s.replaceItems(contextKind, from, to, true)
+}
+
+// replaceItems replaces the from projects or contexts with the to project or context
+// on every todo that has any of them. Todos are updated in place, so they keep their line numbers
+func (s *DefaultTodoService) replaceItems(kind itemKind, from []string, to string, merge bool) ([]Todo, error) {
+	operation := "rename " + kind.name
+	if merge {
+		operation = "merge " + kind.name + "s"
+	}
+
+	for _, item := range append(slices.Clone(from), to) {
+		if kind.re.FindString(item) != item {
+			return nil, fmt.Errorf("failed to %s: invalid %s %q", operation, kind.name, item)
+		}
+	}
+
+	allTodos, err := s.repo.ListAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list all todos: %w", err)
+	}
+	if !merge && !slices.Contains(from, to) {
+		for _, todo := range allTodos {
+			if slices.Contains(kind.items(todo), to) {
+				return nil, fmt.Errorf("failed to %s: %s already exists, merge the %ss instead", operation, to, kind.name)
+			}
+		}
+	}
+
+	before := s.snapshot()
+	updatedTodos := []Todo{}
+
+	for index, todo := range allTodos {
+		items := kind.items(todo)
+		if !slices.ContainsFunc(from, func(item string) bool { return item != to && slices.Contains(items, item) }) {
+			continue
+		}
+
+		for _, item := range from {
+			if item != to {
+				if _, err := kind.remove(s.repo, index, item); err != nil {
+					return nil, fmt.Errorf("failed to remove %s from todo at index %d: %w", item, index, err)
+				}
+			}
+		}
+		todo, err := kind.add(s.repo, index, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s to todo at index %d: %w", to, index, err)
+		}
+		updatedTodos = append(updatedTodos, todo)
+	}
+
+	s.record(operation, before)
+	if err := s.changed(); err != nil {
+		return nil, err
+	}
+
+	return updatedTodos, nil
+}
+
+// TagTodos adds +projects, @contexts and key:value tags to the todos at the given
+// indices (0-based), replacing the value of existing tags with the same key
+// Nothing is changed if any of the tags or indices is invalid
+// Returns the updated todos
+func (s *DefaultTodoService) TagTodos(indices []int, tags []string) ([]Todo, error) {
+	return s.editTags("tag todos", indices, tags, false)
+}
+
+// UntagTodos removes +projects, @contexts and key:value tags from the todos at the
+// given indices (0-based). A tag key without a value removes the tag whatever its value
+// Nothing is changed if any of the tags or indices is invalid
+// Returns the updated todos
+func (s *DefaultTodoService) UntagTodos(indices []int, tags []string) ([]Todo, error) {
+	return s.editTags("untag todos", indices, tags, true)
+}
+
+// editTags adds or removes tags on the todos at the given indices (0-based) and saves once
+func (s *DefaultTodoService) editTags(operation string, indices []int, tags []string, remove bool) ([]Todo, error) {
+	edits := make([]func(todo *Todo), len(tags))
+	for i, tag := range tags {
+		edit, err := tagEdit(tag, remove)
+		if err != nil {
+			return nil, fmt.Errorf("failed to %s: %w", operation, err)
+		}
+		edits[i] = edit
+	}
+	if err := s.checkIndices(indices); err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", operation, err)
+	}
+	allTodos, err := s.repo.ListAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list all todos: %w", err)
+	}
+
+	before := s.snapshot()
+	updatedTodos := make([]Todo, 0, len(indices))
+
+	for _, index := range slices.Compact(slices.Sorted(slices.Values(indices))) {
+		todo := allTodos[index]
+		for _, edit := range edits {
+			edit(&todo)
+		}
+
+		todo, err := s.repo.Update(index, todo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to %s at index %d: %w", operation, index, err)
+		}
+		updatedTodos = append(updatedTodos, todo)
+	}
+
+	s.record(operation, before)
+	if err := s.changed(); err != nil {
+		return nil, err
 	}
 
 	return updatedTodos, nil
 }
 
+// tagEdit returns a function adding or removing a +project, @context or key:value tag
+func tagEdit(tag string, remove bool) (func(todo *Todo), error) {
+	if projectRe.FindString(tag) == tag {
+		if remove {
+			return func(todo *Todo) { todo.RemoveProject(tag) }, nil
+		}
+		return func(todo *Todo) { todo.AddProject(tag) }, nil
+	}
+
+	if contextRe.FindString(tag) == tag {
+		if remove {
+			return func(todo *Todo) { todo.RemoveContext(tag) }, nil
+		}
+		return func(todo *Todo) { todo.AddContext(tag) }, nil
+	}
+
+	if parsed, ok := ParseTag(tag); ok {
+		if remove {
+			return func(todo *Todo) {
+				if value, ok := todo.GetTag(parsed.Key); ok && value == parsed.Value {
+					todo.RemoveTag(parsed.Key)
+				}
+			}, nil
+		}
+		return func(todo *Todo) { todo.SetTag(parsed.Key, parsed.Value) }, nil
+	}
+
+	if remove && tagKeyRe.MatchString(tag) {
+		return func(todo *Todo) { todo.RemoveTag(tag) }, nil
+	}
+
+	return nil, fmt.Errorf("invalid tag %q, expected a +project, @context or key:value", tag)
+}
+
+// checkIndices returns an error if any of the indices (0-based) is out of bounds,
+// so that operations on several todos can fail before changing any of them
+func (s *DefaultTodoService) checkIndices(indices []int) error {
+	if index, ok := s.outOfBounds(indices); ok {
+		return fmt.Errorf("no todo at index %d: index out of bounds", index)
+	}
+	return nil
+}
+
+// outOfBounds returns the first of the indices (0-based) that is out of bounds, if any
+func (s *DefaultTodoService) outOfBounds(indices []int) (int, bool) {
+	allTodos, _ := s.repo.ListAll()
+	for _, index := range indices {
+		if index < 0 || index >= len(allTodos) {
+			return index, true
+		}
+	}
+	return 0, false
+}
+
 // RemoveDoneTodos removes all completed todos
 // Returns the removed todos
 func (s *DefaultTodoService) RemoveDoneTodos() ([]Todo, error) {
+	before := s.snapshot()
+	doneTodos, err := s.removeDone()
+	if err != nil {
+		return nil, err
+	}
+
+	s.repo.SortDefault()
+	s.record("remove done todos", before)
+	if err := s.changed(); err != nil {
+		return nil, err
+	}
+
+	return doneTodos, nil
+}
+
+// ArchiveDoneTodos moves all completed todos to the archive configured WithArchive
+// The archive is written first, so a failure never loses completed todos: if
+// writing the archive fails nothing is removed, and if saving afterwards fails
+// the list is restored as it was and, if the archive can be reverted (as a file
+// written by NewAppendFileWriter can), the todos are taken out of the archive
+// again. The list is always saved straight away, even with autosave disabled, so
+// archived todos are never left in both files. Archiving is recorded in the
+// journal like other changes; undoing it puts the todos back in the list but
+// leaves them in the archive too
+// Returns the archived todos
+func (s *DefaultTodoService) ArchiveDoneTodos() ([]Todo, error) {
+	if s.archive == nil {
+		return nil, fmt.Errorf("no archive configured")
+	}
+
+	doneTodos, err := s.repo.ListDone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list done todos: %w", err)
+	}
+	if len(doneTodos) == 0 {
+		return doneTodos, nil
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock todos: %w", err)
+	}
+	defer unlock()
+
+	before := s.snapshot()
+	if err := s.archive.Write(doneTodos); err != nil {
+		return nil, fmt.Errorf("failed to archive done todos: %w", err)
+	}
+
+	if _, err := s.removeDone(); err != nil {
+		return nil, s.revertArchive(before, err)
+	}
+
+	s.repo.SortDefault()
+	if err := s.repo.Save(); err != nil {
+		return nil, s.revertArchive(before, fmt.Errorf("failed to save todos after archiving: %w", err))
+	}
+	s.dirty = false
+	s.record("archive done todos", before)
+	if err := s.storeJournal(); err != nil {
+		return nil, err
+	}
+
+	return doneTodos, nil
+}
+
+// revertArchive restores the todos as they were before archiving failed with
+// the given error, and takes them out of the archive again if it can
+func (s *DefaultTodoService) revertArchive(before []string, err error) error {
+	if restoreErr := s.replaceAll(before); restoreErr != nil {
+		return fmt.Errorf("%w, and failed to restore the done todos: %v", err, restoreErr)
+	}
+
+	archive, ok := s.archive.(revertibleWriter)
+	if !ok {
+		return fmt.Errorf("%w, done todos were kept but are also in the archive", err)
+	}
+	if revertErr := archive.Revert(); revertErr != nil {
+		return fmt.Errorf("%w, done todos were kept but could not be removed from the archive: %v", err, revertErr)
+	}
+	return fmt.Errorf("%w, done todos were kept", err)
+}
+
+// Undo reverts the last operation recorded in the journal configured WithJournal,
+// keeping any changes made to other todos since. The lock of the repository is
+// held throughout, so other togodo processes cannot change the journal meanwhile
+// Returns the journal entry of the undone operation
+func (s *DefaultTodoService) Undo() (JournalEntry, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return JournalEntry{}, fmt.Errorf("failed to lock todos: %w", err)
+	}
+	defer unlock()
+
+	entries, position, err := s.loadJournal()
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	if position == 0 {
+		return JournalEntry{}, ErrNothingToUndo
+	}
+
+	entry := entries[position-1]
+	if err := s.restore(entry.Added, entry.Removed); err != nil {
+		return JournalEntry{}, fmt.Errorf("failed to undo %s: %w", entry.Operation, err)
+	}
+	s.unsaved = append(s.unsaved, journalChange{move: -1})
+	if err := s.changed(); err != nil {
+		return JournalEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// Redo applies the last operation reverted by Undo again, holding the lock of
+// the repository throughout like Undo
+// Returns the journal entry of the redone operation
+func (s *DefaultTodoService) Redo() (JournalEntry, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return JournalEntry{}, fmt.Errorf("failed to lock todos: %w", err)
+	}
+	defer unlock()
+
+	entries, position, err := s.loadJournal()
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	if position == len(entries) {
+		return JournalEntry{}, ErrNothingToRedo
+	}
+
+	entry := entries[position]
+	if err := s.restore(entry.Removed, entry.Added); err != nil {
+		return JournalEntry{}, fmt.Errorf("failed to redo %s: %w", entry.Operation, err)
+	}
+	s.unsaved = append(s.unsaved, journalChange{move: 1})
+	if err := s.changed(); err != nil {
+		return JournalEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// loadJournal loads the entries and position of the journal configured WithJournal,
+// including the changes to it that are waiting for the todos to be saved
+func (s *DefaultTodoService) loadJournal() ([]JournalEntry, int, error) {
+	if s.journal == nil {
+		return nil, 0, fmt.Errorf("no journal configured")
+	}
+
+	entries, position, err := s.journal.Load()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load journal: %w", err)
+	}
+	entries, position = applyJournalChanges(entries, min(max(position, 0), len(entries)), s.unsaved)
+	return entries, position, nil
+}
+
+// record adds an operation that changed the todos from before to their current
+// state to the journal configured WithJournal, discarding any undone operations.
+// Like undoing and redoing, it only reaches the journal once the todos are saved,
+// so that the journal never gets ahead of todo.txt, see storeJournal
+func (s *DefaultTodoService) record(operation string, before []string) {
+	if s.journal == nil {
+		return
+	}
+
+	after := s.snapshot()
+	if slices.Equal(before, after) {
+		return
+	}
+
+	s.unsaved = append(s.unsaved, journalChange{entry: newJournalEntry(operation, s.now(), before, after)})
+}
+
+// storeJournal stores the changes to the journal that were waiting for the todos
+// to be saved. The caller holds the lock of the repository, so that operations
+// recorded by other togodo processes meanwhile are not lost
+func (s *DefaultTodoService) storeJournal() error {
+	if s.journal == nil || len(s.unsaved) == 0 {
+		return nil
+	}
+
+	entries, position, err := s.loadJournal()
+	if err != nil {
+		return fmt.Errorf("todos were saved but could not be recorded for undo: %w", err)
+	}
+	if err := s.journal.Store(entries, position); err != nil {
+		return fmt.Errorf("todos were saved but could not be recorded for undo: %w", err)
+	}
+	s.unsaved = nil
+	return nil
+}
+
+// snapshot returns the text of every todo, as recorded in the journal
+func (s *DefaultTodoService) snapshot() []string {
+	todos, _ := s.repo.ListAll()
+	return todoLines(todos)
+}
+
+// restore removes and inserts the lines of a journal entry, without saving. Any
+// other changes made to the todos since are kept; if a line to remove has been
+// changed since, nothing is restored
+func (s *DefaultTodoService) restore(remove, insert []JournalLine) error {
+	result, conflicts := applyJournalLines(s.snapshot(), remove, insert)
+	if len(conflicts) > 0 {
+		return fmt.Errorf("the todos have been changed since: %s", strings.Join(conflicts, "; "))
+	}
+
+	return s.replaceAll(result)
+}
+
+// replaceAll replaces every todo with the given lines, without saving
+func (s *DefaultTodoService) replaceAll(lines []string) error {
+	current, err := s.repo.ListAll()
+	if err != nil {
+		return fmt.Errorf("failed to list all todos: %w", err)
+	}
+
+	for i := len(current) - 1; i >= 0; i-- {
+		if _, err := s.repo.Remove(i); err != nil {
+			return fmt.Errorf("failed to remove todo at index %d: %w", i, err)
+		}
+	}
+	for _, line := range lines {
+		if _, err := s.repo.Add(line); err != nil {
+			return fmt.Errorf("failed to add todo: %w", err)
+		}
+	}
+	return nil
+}
+
+// Save saves any changes that have not been saved yet, and then records them in
+// the journal configured WithJournal
+func (s *DefaultTodoService) Save() error {
+	unlock, err := s.lock()
+	if err != nil {
+		return fmt.Errorf("failed to save todos: %w", err)
+	}
+	defer unlock()
+
+	if err := s.repo.Save(); err != nil {
+		return fmt.Errorf("failed to save todos: %w", err)
+	}
+	s.dirty = false
+	return s.storeJournal()
+}
+
+// Reload picks up changes made to the todos by other programs, keeping unsaved changes
+func (s *DefaultTodoService) Reload() error {
+	if err := s.repo.Reload(); err != nil {
+		return fmt.Errorf("failed to reload todos: %w", err)
+	}
+	return nil
+}
+
+// Lock holds the lock of the repository, if it is a Locker, until the returned
+// function is called, and picks up changes made to the todos before it was
+// taken, so that they are read, changed and saved without other togodo
+// processes changing them in between
+func (s *DefaultTodoService) Lock() (func(), error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock todos: %w", err)
+	}
+	if err := s.Reload(); err != nil {
+		unlock()
+		return nil, err
+	}
+	return unlock, nil
+}
+
+// lock holds the lock of the repository, if it is a Locker, until the returned function is called
+func (s *DefaultTodoService) lock() (func(), error) {
+	locker, ok := s.repo.(Locker)
+	if !ok {
+		return func() {}, nil
+	}
+	return locker.Lock()
+}
+
+// Dirty returns whether there are changes that have not been saved yet
+func (s *DefaultTodoService) Dirty() bool {
+	return s.dirty
+}
+
+// changed saves the todos after a change, or marks them as unsaved when autosave is disabled
+func (s *DefaultTodoService) changed() error {
+	if s.manualSave {
+		s.dirty = true
+		return nil
+	}
+	return s.Save()
+}
+
+// removeDone removes all completed todos from the repository without saving
+// Returns the removed todos
+func (s *DefaultTodoService) removeDone() ([]Todo, error) {
 	// Get done todos before removing
 	doneTodos, err := s.repo.ListDone()
 	if err != nil {
@@ -109,16 +928,46 @@ func (s *DefaultTodoService) RemoveDoneTodos() ([]Todo, error) {
 		}
 	}
 
-	s.repo.SortDefault()
-	if err := s.repo.Save(); err != nil {
-		return nil, fmt.Errorf("failed to save todos: %w", err)
-	}
-
 	return doneTodos, nil
 }
 
-// SearchTodos searches for todos matching the given query
-// Returns matching todos
+// SearchTodos searches for todos matching the given query, see ParseQuery
+// Todos with a threshold date in the future are hidden
+// Returns matching todos, or an error if the query is invalid
 func (s *DefaultTodoService) SearchTodos(query string) ([]Todo, error) {
-	return s.repo.Search(query)
+	parsed, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return s.FilterTodos(Filter{Query: parsed, HideFuture: true})
+}
+
+// FilterTodos returns the todos matching the given filter
+// Date criteria are evaluated against the service clock unless the filter sets Today
+func (s *DefaultTodoService) FilterTodos(filter Filter) ([]Todo, error) {
+	if filter.Today.IsZero() {
+		filter.Today = s.now()
+	}
+	return s.repo.Filter(filter)
+}
+
+// DueTodos returns the pending todos that are overdue or due within the given number of days
+// Returns matching todos ordered by due date
+func (s *DefaultTodoService) DueTodos(days int) ([]Todo, error) {
+	todos, err := s.FilterTodos(Filter{
+		Done:      "false",
+		Overdue:   true,
+		DueToday:  true,
+		DueWithin: days,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(todos, func(a, b Todo) int {
+		aDue, _ := a.Due()
+		bDue, _ := b.Due()
+		return aDue.Compare(bDue)
+	})
+	return todos, nil
 }
@@ -1,7 +1,12 @@
 package todotxtlib
 
 import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 // TestService_AddTodos_SingleTask tests adding a single task
@@ -65,6 +70,39 @@ func TestService_AddTodos_AutomaticSorting(t *testing.T) {
 	assertTodoPriority(t, allTodos[2], "C")
 }
 
+// TestService_AddTodos_DateOnAdd tests that added tasks get a creation date when configured
+func TestService_AddTodos_DateOnAdd(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo,
+		WithClock(func() time.Time { return time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local) }),
+		WithDateOnAdd(true),
+	)
+
+	todos, err := service.AddTodos([]string{
+		"(A) task one",
+		"task two",
+		"2024-02-01 task three",
+	})
+
+	assertNoError(t, err)
+	assertTodoText(t, todos[0], "(A) 2024-03-01 task one")
+	assertTodoText(t, todos[1], "2024-03-01 task two")
+	assertTodoText(t, todos[2], "2024-02-01 task three")
+}
+
+// TestService_AddDatedTodos tests that AddDatedTodos stamps tasks regardless of the default
+func TestService_AddDatedTodos(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo, WithClock(func() time.Time {
+		return time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	}))
+
+	todos, err := service.AddDatedTodos([]string{"task one"})
+
+	assertNoError(t, err)
+	assertTodoText(t, todos[0], "2024-03-01 task one")
+}
+
 // TestService_ToggleTodos_SingleTask tests toggling a single task
 func TestService_ToggleTodos_SingleTask(t *testing.T) {
 	repo, _ := setupEmptyTestRepository(t)
@@ -140,7 +178,113 @@ func TestService_ToggleTodos_ToggleBack(t *testing.T) {
 	assertTodoCompleted(t, todos[0], false)
 }
 
-// TestService_ToggleTodos_InvalidIndex tests toggling with an invalid index
+// TestService_ToggleTodos_CompletionDate tests that completed tasks are stamped with the clock's date
+func TestService_ToggleTodos_CompletionDate(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo, WithClock(func() time.Time {
+		return time.Date(2024, 3, 1, 18, 0, 0, 0, time.Local)
+	}))
+
+	service.AddTodos([]string{"(A) 2024-02-01 task one"})
+
+	todos, err := service.ToggleTodos([]int{0})
+
+	assertNoError(t, err)
+	assertTodoText(t, todos[0], "x 2024-03-01 2024-02-01 task one")
+	assertTodoPriority(t, todos[0], "")
+
+	// Reopening removes the completion date again
+	todos, err = service.ToggleTodos([]int{0})
+
+	assertNoError(t, err)
+	assertTodoText(t, todos[0], "2024-02-01 task one")
+}
+
+// TestService_ToggleTodos_PriorityTag tests that the priority survives a round trip when kept in a tag
+func TestService_ToggleTodos_PriorityTag(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo,
+		WithClock(func() time.Time { return time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local) }),
+		WithPriorityTag(true),
+	)
+
+	service.AddTodos([]string{"(B) task one +project"})
+
+	todos, err := service.ToggleTodos([]int{0})
+	assertNoError(t, err)
+	assertTodoText(t, todos[0], "x 2024-03-01 task one +project pri:B")
+
+	todos, err = service.ToggleTodos([]int{0})
+	assertNoError(t, err)
+	assertTodoText(t, todos[0], "(B) task one +project")
+	assertTodoPriority(t, todos[0], "B")
+}
+
+// TestService_ToggleTodos_PriorityTagNotAPriority tests that a pri: tag the user wrote survives a round trip
+func TestService_ToggleTodos_PriorityTagNotAPriority(t *testing.T) {
+	for _, priorityTag := range []bool{false, true} {
+		repo, _ := setupEmptyTestRepository(t)
+		service := NewTodoService(repo,
+			WithClock(func() time.Time { return time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local) }),
+			WithPriorityTag(priorityTag),
+		)
+
+		service.AddTodos([]string{"x 2024-01-01 ship it pri:high +work"})
+
+		todos, err := service.ToggleTodos([]int{0})
+		assertNoError(t, err)
+		assertTodoText(t, todos[0], "ship it pri:high +work")
+
+		todos, err = service.ToggleTodos([]int{0})
+		assertNoError(t, err)
+		assertTodoText(t, todos[0], "x 2024-03-01 ship it pri:high +work")
+
+		todos, err = service.ReopenTodos([]int{0})
+		assertNoError(t, err)
+		assertTodoText(t, todos[0], "ship it pri:high +work")
+	}
+}
+
+// TestService_ReopenTodos_WithoutPriorityTag tests that a pri: tag is left alone unless the service keeps priorities in tags
+func TestService_ReopenTodos_WithoutPriorityTag(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo)
+
+	service.AddTodos([]string{"x 2024-01-10 task one pri:A"})
+
+	todos, err := service.ReopenTodos([]int{0})
+	assertNoError(t, err)
+	assertTodoText(t, todos[0], "task one pri:A")
+}
+
+// TestService_ToggleTodos_Recurring tests that completing a recurring task adds its next occurrence
+func TestService_ToggleTodos_Recurring(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo, WithClock(func() time.Time {
+		return time.Date(2024, 3, 10, 0, 0, 0, 0, time.Local)
+	}))
+
+	service.AddTodos([]string{"(A) water plants due:2024-03-10 rec:1w"})
+
+	todos, err := service.ToggleTodos([]int{0})
+
+	assertNoError(t, err)
+	assertTodoCount(t, todos, 2)
+	assertTodoText(t, todos[0], "x 2024-03-10 water plants due:2024-03-10 rec:1w")
+	assertTodoText(t, todos[1], "(A) water plants due:2024-03-17 rec:1w")
+
+	allTodos, _ := repo.ListAll()
+	assertTodoCount(t, allTodos, 2)
+	assertTodoExists(t, allTodos, "(A) water plants due:2024-03-17 rec:1w")
+
+	// Reopening a recurring task does not add another occurrence
+	todos, err = service.ToggleTodos([]int{1})
+
+	assertNoError(t, err)
+	assertTodoCount(t, todos, 1)
+}
+
+// TestService_ToggleTodos_InvalidIndex tests that nothing is toggled if an index is invalid
 func TestService_ToggleTodos_InvalidIndex(t *testing.T) {
 	repo, _ := setupEmptyTestRepository(t)
 	service := NewTodoService(repo)
@@ -148,9 +292,13 @@ func TestService_ToggleTodos_InvalidIndex(t *testing.T) {
 	service.AddTodos([]string{"(A) task one"})
 
 	// Try to toggle non-existent index
-	_, err := service.ToggleTodos([]int{99})
+	_, err := service.ToggleTodos([]int{0, 99})
 
 	assertError(t, err)
+	allTodos, _ := repo.ListAll()
+	if allTodos[0].Done {
+		t.Error("Expected no task to be toggled")
+	}
 }
 
 // TestService_SetPriorities_SingleTask tests setting priority on a single task
@@ -218,19 +366,40 @@ func TestService_SetPriorities_NoSorting(t *testing.T) {
 	firstTaskText := beforeTodos[0].Text
 	secondTaskText := beforeTodos[1].Text
 
-	// Change priority of third task (C) to AAA
-	// This would move it to first if we sorted
-	service.SetPriorities([]int{2}, "AAA")
+	// Change priority of third task (C) to A
+	// This would move it to first if we sorted, as "(A) task one" < "(A) task three"
+	service.SetPriorities([]int{2}, "A")
 
 	// Verify order is preserved (no sorting after SetPriorities)
 	allTodos, _ := repo.ListAll()
 	assertTodoText(t, allTodos[0], firstTaskText)
 	assertTodoText(t, allTodos[1], secondTaskText)
 	// Third task should have new priority but stay in same position
-	assertTodoPriority(t, allTodos[2], "AAA")
+	assertTodoPriority(t, allTodos[2], "A")
 }
 
-// TestService_SetPriorities_InvalidIndex tests setting priority with invalid index
+// TestService_SetPriorities_InvalidPriority tests that only single letters are accepted as priorities
+func TestService_SetPriorities_InvalidPriority(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo)
+
+	service.AddTodos([]string{"task one"})
+
+	for _, priority := range []string{"foo", "(A)", "1", "AB"} {
+		_, err := service.SetPriorities([]int{0}, priority)
+		assertError(t, err)
+		assertContains(t, err.Error(), "invalid priority")
+	}
+	allTodos, _ := repo.ListAll()
+	assertTodoText(t, allTodos[0], "task one")
+
+	todos, err := service.SetPriorities([]int{0}, "b")
+	assertNoError(t, err)
+	assertTodoText(t, todos[0], "(B) task one")
+	assertTodoPriority(t, todos[0], "B")
+}
+
+// TestService_SetPriorities_InvalidIndex tests that no priority is set if an index is invalid
 func TestService_SetPriorities_InvalidIndex(t *testing.T) {
 	repo, _ := setupEmptyTestRepository(t)
 	service := NewTodoService(repo)
@@ -238,9 +407,284 @@ func TestService_SetPriorities_InvalidIndex(t *testing.T) {
 	service.AddTodos([]string{"task one"})
 
 	// Try to set priority on non-existent index
-	_, err := service.SetPriorities([]int{99}, "A")
+	_, err := service.SetPriorities([]int{0, 99}, "A")
 
 	assertError(t, err)
+	allTodos, _ := repo.ListAll()
+	assertTodoPriority(t, allTodos[0], "")
+}
+
+// TestService_DeprioritizeTodos tests removing the priority of tasks
+func TestService_DeprioritizeTodos(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo)
+
+	service.AddTodos([]string{"(A) task one", "(B) task two", "(C) task three"})
+
+	todos, err := service.DeprioritizeTodos([]int{0, 2})
+
+	assertNoError(t, err)
+	assertTodoCount(t, todos, 2)
+	assertTodoPriority(t, todos[0], "")
+	assertTodoPriority(t, todos[1], "")
+
+	// Not sorted afterwards, so tasks keep their line numbers
+	expectedOutput := "task one\n(B) task two\ntask three\n"
+	if output, _ := repo.WriteToString(); output != expectedOutput {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+// TestService_DeprioritizeTodos_InvalidIndex tests that no priority is removed if an index is invalid
+func TestService_DeprioritizeTodos_InvalidIndex(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo)
+
+	service.AddTodos([]string{"(A) task one"})
+
+	_, err := service.DeprioritizeTodos([]int{0, 99})
+
+	assertError(t, err)
+	allTodos, _ := repo.ListAll()
+	assertTodoPriority(t, allTodos[0], "A")
+}
+
+// TestService_ReopenTodos tests marking done tasks as not done
+func TestService_ReopenTodos(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo, WithPriorityTag(true))
+
+	service.AddTodos([]string{"task one", "x 2024-01-10 2024-01-01 task two pri:A"})
+
+	todos, err := service.ReopenTodos([]int{0, 1})
+
+	assertNoError(t, err)
+	assertTodoCount(t, todos, 2)
+	assertTodoText(t, todos[0], "task one")
+	assertTodoText(t, todos[1], "(A) 2024-01-01 task two")
+
+	expectedOutput := "(A) 2024-01-01 task two\ntask one\n"
+	if output, _ := repo.WriteToString(); output != expectedOutput {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+// TestService_ReopenTodos_InvalidIndex tests reopening a non-existent task
+func TestService_ReopenTodos_InvalidIndex(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo)
+
+	service.AddTodos([]string{"x task one"})
+
+	_, err := service.ReopenTodos([]int{0, 99})
+
+	assertError(t, err)
+	allTodos, _ := repo.ListAll()
+	assertTodoText(t, allTodos[0], "x task one")
+}
+
+// TestService_RemoveTodos tests deleting tasks
+func TestService_RemoveTodos(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo)
+
+	service.AddTodos([]string{"task one", "task two", "task three"})
+
+	todos, err := service.RemoveTodos([]int{2, 0, 2})
+
+	assertNoError(t, err)
+	assertTodoCount(t, todos, 2)
+	assertTodoText(t, todos[0], "task one")
+	assertTodoText(t, todos[1], "task two")
+
+	expectedOutput := "task three\n"
+	if output, _ := repo.WriteToString(); output != expectedOutput {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+// TestService_RemoveTodos_InvalidIndex tests that nothing is removed if an index is invalid
+func TestService_RemoveTodos_InvalidIndex(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo)
+
+	service.AddTodos([]string{"task one", "task two"})
+
+	_, err := service.RemoveTodos([]int{0, 99})
+
+	assertError(t, err)
+	allTodos, _ := repo.ListAll()
+	assertTodoCount(t, allTodos, 2)
+}
+
+// TestService_EditTodo tests replacing, appending to and prepending to a task
+func TestService_EditTodo(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		edit     func(service TodoService) (Todo, error)
+		expected string
+	}{
+		{
+			name: "replace",
+			text: "(A) 2024-01-01 call mum",
+			edit: func(service TodoService) (Todo, error) {
+				return service.ReplaceTodo(0, "(B) call dad @phone")
+			},
+			expected: "(B) call dad @phone",
+		},
+		{
+			name: "append",
+			text: "(A) call mum",
+			edit: func(service TodoService) (Todo, error) {
+				return service.AppendToTodo(0, "@phone +family")
+			},
+			expected: "(A) call mum @phone +family",
+		},
+		{
+			name: "prepend",
+			text: "(A) 2024-01-01 call mum",
+			edit: func(service TodoService) (Todo, error) {
+				return service.PrependToTodo(0, "urgently")
+			},
+			expected: "(A) 2024-01-01 urgently call mum",
+		},
+		{
+			name: "prepend to done",
+			text: "x 2024-01-10 2024-01-01 call mum",
+			edit: func(service TodoService) (Todo, error) {
+				return service.PrependToTodo(0, "urgently")
+			},
+			expected: "x 2024-01-10 2024-01-01 urgently call mum",
+		},
+		{
+			name: "prepend without prefix",
+			text: "call mum",
+			edit: func(service TodoService) (Todo, error) {
+				return service.PrependToTodo(0, "(A) urgently")
+			},
+			expected: "(A) urgently call mum",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, _ := setupEmptyTestRepository(t)
+			service := NewTodoService(repo)
+			service.AddTodos([]string{tt.text})
+
+			todo, err := tt.edit(service)
+
+			assertNoError(t, err)
+			assertTodoText(t, todo, tt.expected)
+			if todo.LineNumber != 1 {
+				t.Errorf("Expected line number 1, got %d", todo.LineNumber)
+			}
+			if output, _ := repo.WriteToString(); output != tt.expected+"\n" {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, output)
+			}
+		})
+	}
+}
+
+// TestService_EditTodo_InvalidIndex tests editing a non-existent task
+func TestService_EditTodo_InvalidIndex(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo)
+
+	service.AddTodos([]string{"task one"})
+
+	if _, err := service.ReplaceTodo(99, "task two"); err == nil {
+		t.Error("ReplaceTodo() expected error for invalid index")
+	}
+	if _, err := service.AppendToTodo(-1, "task two"); err == nil {
+		t.Error("AppendToTodo() expected error for invalid index")
+	}
+	if _, err := service.PrependToTodo(1, "task two"); err == nil {
+		t.Error("PrependToTodo() expected error for invalid index")
+	}
+}
+
+// TestService_RenameProject tests renaming a project across all tasks
+func TestService_RenameProject(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo)
+
+	service.AddTodos([]string{"(A) fix +web layout @work", "update +website", "x ship +web"})
+
+	todos, err := service.RenameProject("+web", "+site")
+
+	assertNoError(t, err)
+	assertTodoCount(t, todos, 2)
+
+	expectedOutput := "(A) fix layout @work +site\nupdate +website\nx ship +site\n"
+	if output, _ := repo.WriteToString(); output != expectedOutput {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+// TestService_RenameProject_Exists tests that renaming to an existing project fails
+func TestService_RenameProject_Exists(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo)
+
+	service.AddTodos([]string{"task one +a", "task two +b"})
+
+	_, err := service.RenameProject("+a", "+b")
+	assertError(t, err)
+
+	_, err = service.RenameProject("+a", "not a project")
+	assertError(t, err)
+
+	allTodos, _ := repo.ListAll()
+	assertTodoText(t, allTodos[0], "task one +a")
+}
+
+// TestService_MergeContexts tests merging several contexts into one
+func TestService_MergeContexts(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo, WithJournal(NewMemoryJournal()))
+
+	service.AddTodos([]string{"call mum @phone", "email bob @email @phone", "buy milk @shop", "text sam @calls"})
+
+	todos, err := service.MergeContexts([]string{"@phone", "@email"}, "@calls")
+
+	assertNoError(t, err)
+	assertTodoCount(t, todos, 2)
+
+	expectedOutput := "buy milk @shop\ncall mum @calls\nemail bob @calls\ntext sam @calls\n"
+	if output, _ := repo.WriteToString(); output != expectedOutput {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+
+	entry, err := service.Undo()
+	assertNoError(t, err)
+	if entry.Operation != "merge contexts" {
+		t.Errorf("Expected operation %q, got %q", "merge contexts", entry.Operation)
+	}
+}
+
+// TestService_TagTodos_SingleSave tests that tagging several tasks saves them all at once
+func TestService_TagTodos_SingleSave(t *testing.T) {
+	repo, buf := setupEmptyTestRepository(t)
+	service := NewTodoService(repo)
+
+	service.AddTodos([]string{"task one @home", "task two", "task three"})
+	buf.Reset()
+
+	todos, err := service.TagTodos([]int{2, 0}, []string{"+chores", "@work", "due:2024-01-31"})
+
+	assertNoError(t, err)
+	assertTodoCount(t, todos, 2)
+	assertTodoText(t, todos[0], "task one @home +chores @work due:2024-01-31")
+
+	expectedOutput := "task one @home +chores @work due:2024-01-31\ntask three\ntask two +chores @work due:2024-01-31\n"
+	if output := buf.String(); output != expectedOutput {
+		t.Errorf("Expected a single save of:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+
+	_, err = service.TagTodos([]int{0, 99}, []string{"+more"})
+	assertError(t, err)
 }
 
 // TestService_RemoveDoneTodos_EmptyList tests removing done todos from empty list
@@ -420,3 +864,382 @@ func TestService_Integration_FullWorkflow(t *testing.T) {
 	allTodos, _ := repo.ListAll()
 	assertTodoCount(t, allTodos, 2)
 }
+
+// TestService_DueTodos tests listing overdue and upcoming todos ordered by due date
+func TestService_DueTodos(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo, WithClock(func() time.Time {
+		return time.Date(2024, 3, 10, 0, 0, 0, 0, time.Local)
+	}))
+
+	service.AddTodos([]string{
+		"(A) due soon due:2024-03-12",
+		"overdue due:2024-03-01",
+		"due later due:2024-05-01",
+		"x done overdue due:2024-03-02",
+		"no due date",
+	})
+
+	todos, err := service.DueTodos(7)
+
+	assertNoError(t, err)
+	assertTodoCount(t, todos, 2)
+	assertTodoText(t, todos[0], "overdue due:2024-03-01")
+	assertTodoText(t, todos[1], "(A) due soon due:2024-03-12")
+}
+
+// TestService_SearchTodos_HidesFutureThreshold tests that parked todos are hidden from searches
+func TestService_SearchTodos_HidesFutureThreshold(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo, WithClock(func() time.Time {
+		return time.Date(2024, 3, 10, 0, 0, 0, 0, time.Local)
+	}))
+
+	service.AddTodos([]string{
+		"task now t:2024-03-10",
+		"task later t:2024-03-11",
+	})
+
+	todos, err := service.SearchTodos("task")
+	assertNoError(t, err)
+	assertTodoCount(t, todos, 1)
+	assertTodoText(t, todos[0], "task now t:2024-03-10")
+
+	todos, err = service.FilterTodos(Filter{Text: "task"})
+	assertNoError(t, err)
+	assertTodoCount(t, todos, 2)
+}
+
+// TestService_ArchiveDoneTodos tests moving done todos to the archive
+func TestService_ArchiveDoneTodos(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	var archive bytes.Buffer
+	service := NewTodoService(repo, WithArchive(NewBufferWriter(&archive)))
+
+	todos, err := service.ArchiveDoneTodos()
+
+	assertNoError(t, err)
+	assertTodoCount(t, todos, 1)
+	assertTodoText(t, todos[0], "x (C) test todo 3 +project1 @context1")
+
+	if archive.String() != "x (C) test todo 3 +project1 @context1\n" {
+		t.Errorf("Expected done todo in archive, got:\n%s", archive.String())
+	}
+
+	allTodos, _ := repo.ListAll()
+	assertTodoCount(t, allTodos, 2)
+	assertTodoNotExists(t, allTodos, "x (C) test todo 3 +project1 @context1")
+}
+
+// TestService_ArchiveDoneTodos_Undo tests that archiving is recorded in the journal,
+// so that it and the changes before it can be undone
+func TestService_ArchiveDoneTodos_Undo(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	var archive bytes.Buffer
+	service := NewTodoService(repo, WithArchive(NewBufferWriter(&archive)), WithJournal(NewMemoryJournal()))
+
+	_, err := service.AddTodos([]string{"task one", "task two"})
+	assertNoError(t, err)
+	_, err = service.ToggleTodos([]int{0})
+	assertNoError(t, err)
+	_, err = service.ArchiveDoneTodos()
+	assertNoError(t, err)
+
+	for _, operation := range []string{"archive done todos", "toggle todos", "add todos"} {
+		entry, err := service.Undo()
+		assertNoError(t, err)
+		if entry.Operation != operation {
+			t.Errorf("Expected to undo %s, got %q", operation, entry.Operation)
+		}
+	}
+
+	allTodos, _ := repo.ListAll()
+	assertTodoCount(t, allTodos, 0)
+}
+
+// TestService_ArchiveDoneTodos_NoArchive tests archiving without an archive configured
+func TestService_ArchiveDoneTodos_NoArchive(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := NewTodoService(repo)
+
+	_, err := service.ArchiveDoneTodos()
+
+	assertError(t, err)
+	assertContains(t, err.Error(), "no archive configured")
+}
+
+// TestService_ArchiveDoneTodos_ArchiveFails tests that nothing is removed if the archive cannot be written
+func TestService_ArchiveDoneTodos_ArchiveFails(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := NewTodoService(repo, WithArchive(failingWriter{}))
+
+	_, err := service.ArchiveDoneTodos()
+
+	assertError(t, err)
+	assertContains(t, err.Error(), "failed to archive done todos")
+
+	allTodos, _ := repo.ListAll()
+	assertTodoCount(t, allTodos, 3)
+}
+
+// TestService_ArchiveDoneTodos_SaveFails tests that done todos are kept where they were if saving fails after archiving
+func TestService_ArchiveDoneTodos_SaveFails(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("x task two\ntask one\n")
+	repo, err := NewFileRepository(NewBufferReader(&buf), failingWriter{})
+	assertNoError(t, err)
+
+	var archive bytes.Buffer
+	service := NewTodoService(repo, WithArchive(NewBufferWriter(&archive)))
+
+	_, err = service.ArchiveDoneTodos()
+
+	assertError(t, err)
+	assertContains(t, err.Error(), "done todos were kept")
+
+	if output, _ := repo.WriteToString(); output != "x task two\ntask one\n" {
+		t.Errorf("Expected todos to be restored in their original order, got:\n%s", output)
+	}
+}
+
+// TestService_ArchiveDoneTodos_SaveFailsRevertsArchive tests that done todos are taken
+// out of done.txt again if saving fails, so that retrying does not archive them twice
+func TestService_ArchiveDoneTodos_SaveFailsRevertsArchive(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("task one\nx task two\n")
+	repo, err := NewFileRepository(NewBufferReader(&buf), failingWriter{})
+	assertNoError(t, err)
+
+	donePath := filepath.Join(t.TempDir(), "done.txt")
+	if err := os.WriteFile(donePath, []byte("x archived before\n"), 0644); err != nil {
+		t.Fatalf("failed to write done.txt: %v", err)
+	}
+	service := NewTodoService(repo, WithArchive(NewAppendFileWriter(donePath)))
+
+	for range 2 {
+		_, err = service.ArchiveDoneTodos()
+		assertError(t, err)
+		assertContains(t, err.Error(), "done todos were kept")
+	}
+
+	content, err := os.ReadFile(donePath)
+	assertNoError(t, err)
+	if string(content) != "x archived before\n" {
+		t.Errorf("Expected done.txt to be unchanged, got:\n%s", content)
+	}
+	allTodos, _ := repo.ListAll()
+	assertTodoCount(t, allTodos, 2)
+}
+
+// TestService_AutoSave tests that changes are saved straight away by default
+func TestService_AutoSave(t *testing.T) {
+	repo, buf := setupEmptyTestRepository(t)
+	service := NewTodoService(repo)
+
+	_, err := service.AddTodos([]string{"task one"})
+
+	assertNoError(t, err)
+	if buf.String() != "task one\n" {
+		t.Errorf("Expected todo to be saved, got:\n%s", buf.String())
+	}
+	if service.Dirty() {
+		t.Error("Expected service not to be dirty after saving")
+	}
+}
+
+// TestService_ManualSave tests that changes are kept in memory until Save is called
+func TestService_ManualSave(t *testing.T) {
+	repo, buf := setupEmptyTestRepository(t)
+	service := NewTodoService(repo, WithAutoSave(false))
+
+	_, err := service.AddTodos([]string{"task one"})
+	assertNoError(t, err)
+	_, err = service.ToggleTodos([]int{0})
+	assertNoError(t, err)
+
+	if buf.String() != "" {
+		t.Errorf("Expected nothing to be saved yet, got:\n%s", buf.String())
+	}
+	if !service.Dirty() {
+		t.Error("Expected service to be dirty before saving")
+	}
+
+	assertNoError(t, service.Save())
+
+	assertContains(t, buf.String(), "task one")
+	if service.Dirty() {
+		t.Error("Expected service not to be dirty after saving")
+	}
+}
+
+// TestService_ManualSave_SaveFails tests that changes stay unsaved if saving fails
+func TestService_ManualSave_SaveFails(t *testing.T) {
+	repo, err := NewFileRepository(NewBufferReader(&bytes.Buffer{}), failingWriter{})
+	assertNoError(t, err)
+	service := NewTodoService(repo, WithAutoSave(false))
+
+	_, err = service.AddTodos([]string{"task one"})
+	assertNoError(t, err)
+
+	err = service.Save()
+
+	assertError(t, err)
+	assertContains(t, err.Error(), "failed to save todos")
+	if !service.Dirty() {
+		t.Error("Expected service to stay dirty after a failed save")
+	}
+}
+
+// TestService_UndoRedo tests undoing and redoing operations recorded in the journal
+func TestService_UndoRedo(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	service := NewTodoService(repo, WithJournal(NewMemoryJournal()))
+	original, _ := repo.WriteToString()
+
+	_, err := service.RemoveDoneTodos()
+	assertNoError(t, err)
+	_, err = service.SetPriorities([]int{0}, "C")
+	assertNoError(t, err)
+	changed, _ := repo.WriteToString()
+
+	entry, err := service.Undo()
+	assertNoError(t, err)
+	if entry.Operation != "set priorities" {
+		t.Errorf("Expected to undo set priorities, got %q", entry.Operation)
+	}
+	entry, err = service.Undo()
+	assertNoError(t, err)
+	if entry.Operation != "remove done todos" {
+		t.Errorf("Expected to undo remove done todos, got %q", entry.Operation)
+	}
+	if output, _ := repo.WriteToString(); output != original {
+		t.Errorf("Expected undo to restore:\n%s\nGot:\n%s", original, output)
+	}
+
+	_, err = service.Undo()
+	if !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+
+	_, err = service.Redo()
+	assertNoError(t, err)
+	_, err = service.Redo()
+	assertNoError(t, err)
+	if output, _ := repo.WriteToString(); output != changed {
+		t.Errorf("Expected redo to restore:\n%s\nGot:\n%s", changed, output)
+	}
+
+	_, err = service.Redo()
+	if !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("Expected ErrNothingToRedo, got %v", err)
+	}
+}
+
+// TestService_Undo_DiscardsRedo tests that a new operation discards undone operations
+func TestService_Undo_DiscardsRedo(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo, WithJournal(NewMemoryJournal()))
+
+	_, err := service.AddTodos([]string{"task one"})
+	assertNoError(t, err)
+	_, err = service.Undo()
+	assertNoError(t, err)
+	_, err = service.AddTodos([]string{"task two"})
+	assertNoError(t, err)
+
+	_, err = service.Redo()
+	if !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("Expected ErrNothingToRedo, got %v", err)
+	}
+}
+
+// TestService_Undo_KeepsLaterChanges tests that undo merges with changes made since the operation
+func TestService_Undo_KeepsLaterChanges(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo, WithJournal(NewMemoryJournal()), WithClock(func() time.Time {
+		return time.Date(2024, 1, 15, 9, 30, 0, 0, time.Local)
+	}))
+
+	_, err := service.AddTodos([]string{"task one", "task two"})
+	assertNoError(t, err)
+	_, err = service.ToggleTodos([]int{0})
+	assertNoError(t, err)
+	repo.Add("task three")
+
+	_, err = service.Undo()
+	assertNoError(t, err)
+
+	allTodos, _ := repo.ListAll()
+	assertTodoCount(t, allTodos, 3)
+	assertTodoExists(t, allTodos, "task one")
+	assertTodoExists(t, allTodos, "task three")
+}
+
+// TestService_Undo_Conflict tests that undo fails if the changed todos were changed again
+func TestService_Undo_Conflict(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo, WithJournal(NewMemoryJournal()))
+
+	_, err := service.AddTodos([]string{"task one"})
+	assertNoError(t, err)
+	_, err = service.SetPriorities([]int{0}, "A")
+	assertNoError(t, err)
+	repo.SetPriority(0, "B")
+
+	_, err = service.Undo()
+
+	assertError(t, err)
+	assertContains(t, err.Error(), "failed to undo set priorities")
+	allTodos, _ := repo.ListAll()
+	assertTodoText(t, allTodos[0], "(B) task one")
+}
+
+// TestService_ManualSave_Journal tests that changes, undos and redos only reach the
+// journal once the todos are saved, so quitting without saving leaves it matching todo.txt
+func TestService_ManualSave_Journal(t *testing.T) {
+	repo, _ := setupTestRepository(t)
+	journal := NewMemoryJournal()
+	service := NewTodoService(repo, WithJournal(journal), WithAutoSave(false))
+	assertJournal := func(wantEntries, wantPosition int) {
+		t.Helper()
+		entries, position, _ := journal.Load()
+		if len(entries) != wantEntries || position != wantPosition {
+			t.Errorf("Expected %d journal entries at position %d, got %d at %d", wantEntries, wantPosition, len(entries), position)
+		}
+	}
+
+	_, err := service.SetPriorities([]int{0}, "C")
+	assertNoError(t, err)
+	assertJournal(0, 0)
+
+	// Unsaved changes can still be undone and redone
+	entry, err := service.Undo()
+	assertNoError(t, err)
+	if entry.Operation != "set priorities" {
+		t.Errorf("Expected to undo set priorities, got %q", entry.Operation)
+	}
+	_, err = service.Redo()
+	assertNoError(t, err)
+	assertJournal(0, 0)
+
+	assertNoError(t, service.Save())
+	assertJournal(1, 1)
+
+	_, err = service.Undo()
+	assertNoError(t, err)
+	assertJournal(1, 1)
+
+	assertNoError(t, service.Save())
+	assertJournal(1, 0)
+}
+
+// TestService_Undo_NoJournal tests undoing without a journal configured
+func TestService_Undo_NoJournal(t *testing.T) {
+	repo, _ := setupEmptyTestRepository(t)
+	service := NewTodoService(repo)
+
+	_, err := service.Undo()
+
+	assertError(t, err)
+	assertContains(t, err.Error(), "no journal configured")
+}
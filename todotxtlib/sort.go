@@ -1,7 +1,11 @@
 package todotxtlib
 
 import (
-	"sort"
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 )
 
 // sortOrder represents the order in which to sort
@@ -17,13 +21,51 @@ type sortField int
 
 const (
 	Text sortField = iota
-	// TODO: Add other fields
+	Priority
+	Due
+	CreatedAt
+	CompletedAt
+	Project
+	Context
+	TagValue
+	LineNumber
+	Done
 )
 
+// sortFieldNames maps the names used in sort specifications to fields
+var sortFieldNames = map[string]sortField{
+	"text":      Text,
+	"priority":  Priority,
+	"due":       Due,
+	"created":   CreatedAt,
+	"completed": CompletedAt,
+	"project":   Project,
+	"context":   Context,
+	"line":      LineNumber,
+	"done":      Done,
+}
+
+// nilOrder represents where todos without a value for the sort field are placed
+type nilOrder int
+
+const (
+	NilsLast nilOrder = iota
+	NilsFirst
+)
+
+// SortKey is a single field of a multi-field sort
+type SortKey struct {
+	Field sortField // Field to sort by, e.g. Due
+	Order sortOrder // Order to sort by, e.g. Ascending
+	Nils  nilOrder  // Where todos without a value are placed, regardless of Order
+	Tag   string    // Tag key to sort by when Field is TagValue
+}
+
 // Sort represents sorting criteria for todos
 type Sort struct {
-	Field sortField // Field to sort by, e.g. Text
+	Field sortField // Field to sort by, e.g. Text, with done todos placed according to Order
 	Order sortOrder // Order to sort by, e.g. Ascending
+	Keys  []SortKey // Fields to sort by in order of precedence; if set, Field and Order are ignored
 }
 
 // NewDefaultSort returns the default todo.txt sorting (alphabetical with done items last)
@@ -34,23 +76,180 @@ func NewDefaultSort() Sort {
 	}
 }
 
+// ParseSort parses a comma separated sort specification such as "due,priority".
+// Each key is a field name (text, priority, due, created, completed, project,
+// context, line or done) or tag:KEY to sort by the value of a tag. A leading -
+// sorts in descending order, and a :first or :last suffix places todos without
+// a value first or last (the default).
+func ParseSort(spec string) (Sort, error) {
+	keys := []SortKey{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key := SortKey{}
+		if name, ok := strings.CutPrefix(part, "-"); ok {
+			key.Order = Descending
+			part = name
+		}
+
+		fields := strings.Split(part, ":")
+		name, options := fields[0], fields[1:]
+		if name == "tag" {
+			if len(options) == 0 || options[0] == "" {
+				return Sort{}, fmt.Errorf("missing tag key in sort key %q", part)
+			}
+			key.Field = TagValue
+			key.Tag = options[0]
+			options = options[1:]
+		} else {
+			field, ok := sortFieldNames[name]
+			if !ok {
+				return Sort{}, fmt.Errorf("unknown sort field %q", name)
+			}
+			key.Field = field
+		}
+
+		for _, option := range options {
+			switch option {
+			case "first":
+				key.Nils = NilsFirst
+			case "last":
+				key.Nils = NilsLast
+			default:
+				return Sort{}, fmt.Errorf("unknown sort option %q in sort key %q", option, part)
+			}
+		}
+
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return Sort{}, fmt.Errorf("empty sort specification")
+	}
+	return Sort{Keys: keys}, nil
+}
+
 // Apply sorts the todos according to the specified criteria
 func (s Sort) Apply(todos []Todo) {
-	if s.Field == Text {
-		sort.SliceStable(todos, func(i, j int) bool {
-			if todos[i].Done != todos[j].Done {
-				if s.Order == Descending {
-					return todos[i].Done
-				} else {
-					return !todos[i].Done
-				}
-			}
+	keys := s.keys()
 
-			if s.Order == Descending {
-				return todos[i].Text > todos[j].Text
-			} else {
-				return todos[i].Text < todos[j].Text
+	// Sort a slice of indices so todos without a line number can be ordered by position
+	order := make([]int, len(todos))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		for _, key := range keys {
+			if c := key.compare(todos[a], a, todos[b], b); c != 0 {
+				return c
 			}
-		})
+		}
+		return 0
+	})
+
+	sorted := make([]Todo, len(todos))
+	for i, index := range order {
+		sorted[i] = todos[index]
+	}
+	copy(todos, sorted)
+}
+
+// keys returns the sort keys, translating a single Field and Order into keys
+func (s Sort) keys() []SortKey {
+	if len(s.Keys) > 0 {
+		return s.Keys
+	}
+	return []SortKey{
+		{Field: Done, Order: s.Order},
+		{Field: s.Field, Order: s.Order},
+	}
+}
+
+// compare compares two todos by the key, given their positions in the list
+func (k SortKey) compare(a Todo, aPosition int, b Todo, bPosition int) int {
+	aValue, aOk := k.value(a, aPosition)
+	bValue, bOk := k.value(b, bPosition)
+
+	switch {
+	case !aOk && !bOk:
+		return 0
+	case !aOk:
+		return k.nilComparison()
+	case !bOk:
+		return -k.nilComparison()
+	}
+
+	c := compareValues(aValue, bValue)
+	if k.Order == Descending {
+		return -c
+	}
+	return c
+}
+
+// nilComparison returns the comparison result for a todo without a value
+// compared to one with a value
+func (k SortKey) nilComparison() int {
+	if k.Nils == NilsFirst {
+		return -1
+	}
+	return 1
+}
+
+// value returns the value of the key's field for a todo, and whether it has one
+func (k SortKey) value(todo Todo, position int) (any, bool) {
+	switch k.Field {
+	case Priority:
+		return todo.Priority, todo.Priority != ""
+	case Due:
+		return todo.Due()
+	case CreatedAt:
+		return todo.CreatedAt, !todo.CreatedAt.IsZero()
+	case CompletedAt:
+		return todo.CompletedAt, !todo.CompletedAt.IsZero()
+	case Project:
+		if len(todo.Projects) == 0 {
+			return nil, false
+		}
+		return slices.Min(todo.Projects), true
+	case Context:
+		if len(todo.Contexts) == 0 {
+			return nil, false
+		}
+		return slices.Min(todo.Contexts), true
+	case TagValue:
+		return todo.GetTag(k.Tag)
+	case LineNumber:
+		if todo.LineNumber > 0 {
+			return todo.LineNumber, true
+		}
+		return position + 1, true
+	case Done:
+		return todo.Done, true
+	default:
+		return todo.Text, true
+	}
+}
+
+// compareValues compares two values of the same field
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case string:
+		return cmp.Compare(a, b.(string))
+	case int:
+		return cmp.Compare(a, b.(int))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case bool:
+		if a == b.(bool) {
+			return 0
+		}
+		if a {
+			return 1
+		}
+		return -1
 	}
+	return 0
 }
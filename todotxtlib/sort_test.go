@@ -1,6 +1,7 @@
 package todotxtlib
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -245,3 +246,145 @@ func TestSort_Apply(t *testing.T) {
 		})
 	}
 }
+
+func TestSort_ApplyKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		input    []string
+		expected []string
+	}{
+		{
+			name:     "due ascending with missing dates last",
+			spec:     "due",
+			input:    []string{"no due", "b due:2024-02-01", "a due:2024-01-01"},
+			expected: []string{"a due:2024-01-01", "b due:2024-02-01", "no due"},
+		},
+		{
+			name:     "due descending keeps missing dates last",
+			spec:     "-due",
+			input:    []string{"no due", "a due:2024-01-01", "b due:2024-02-01"},
+			expected: []string{"b due:2024-02-01", "a due:2024-01-01", "no due"},
+		},
+		{
+			name:     "missing values first",
+			spec:     "due:first",
+			input:    []string{"a due:2024-01-01", "no due"},
+			expected: []string{"no due", "a due:2024-01-01"},
+		},
+		{
+			name:     "priority then text",
+			spec:     "priority,-text",
+			input:    []string{"no priority", "(B) a", "(A) b", "(A) c"},
+			expected: []string{"(A) c", "(A) b", "(B) a", "no priority"},
+		},
+		{
+			name:     "due then priority, most important first",
+			spec:     "due,priority",
+			input:    []string{"(C) b due:2024-01-01", "(A) a due:2024-01-01", "d due:2024-01-01", "c due:2023-12-01"},
+			expected: []string{"c due:2023-12-01", "(A) a due:2024-01-01", "(C) b due:2024-01-01", "d due:2024-01-01"},
+		},
+		{
+			name:     "descending priority lists the least important first",
+			spec:     "due,-priority",
+			input:    []string{"(A) a due:2024-01-01", "(C) b due:2024-01-01", "c due:2023-12-01"},
+			expected: []string{"c due:2023-12-01", "(C) b due:2024-01-01", "(A) a due:2024-01-01"},
+		},
+		{
+			name:     "creation and completion dates",
+			spec:     "completed,created",
+			input:    []string{"2024-01-02 b", "x 2024-01-05 2024-01-01 done", "2024-01-01 a"},
+			expected: []string{"x 2024-01-05 2024-01-01 done", "2024-01-01 a", "2024-01-02 b"},
+		},
+		{
+			name:     "project and context",
+			spec:     "project,context",
+			input:    []string{"none", "b +beta @home", "a +alpha @work", "c +alpha @home"},
+			expected: []string{"c +alpha @home", "a +alpha @work", "b +beta @home", "none"},
+		},
+		{
+			name:     "tag value",
+			spec:     "tag:estimate",
+			input:    []string{"none", "b estimate:3", "a estimate:1"},
+			expected: []string{"a estimate:1", "b estimate:3", "none"},
+		},
+		{
+			name:     "line number descending",
+			spec:     "-line",
+			input:    []string{"first", "second", "third"},
+			expected: []string{"third", "second", "first"},
+		},
+		{
+			name:     "done last then text",
+			spec:     "done,text",
+			input:    []string{"x done", "b", "a"},
+			expected: []string{"a", "b", "x done"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := ParseSort(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSort(%q) error = %v", tt.spec, err)
+			}
+
+			todos := make([]Todo, len(tt.input))
+			for i, text := range tt.input {
+				todos[i] = NewTodo(text)
+			}
+
+			sort.Apply(todos)
+
+			for i := range todos {
+				if todos[i].Text != tt.expected[i] {
+					t.Errorf("Sort.Apply() todo[%d] = %q, want %q", i, todos[i].Text, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []SortKey
+		wantErr bool
+	}{
+		{
+			name: "multiple keys",
+			spec: "due,-priority",
+			want: []SortKey{{Field: Due}, {Field: Priority, Order: Descending}},
+		},
+		{
+			name: "tag with nil placement",
+			spec: "-tag:estimate:first",
+			want: []SortKey{{Field: TagValue, Order: Descending, Nils: NilsFirst, Tag: "estimate"}},
+		},
+		{
+			name: "whitespace and empty keys are ignored",
+			spec: " created , ,line ",
+			want: []SortKey{{Field: CreatedAt}, {Field: LineNumber}},
+		},
+		{name: "empty specification", spec: "", wantErr: true},
+		{name: "unknown field", spec: "size", wantErr: true},
+		{name: "unknown option", spec: "due:middle", wantErr: true},
+		{name: "missing tag key", spec: "tag", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSort(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSort(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !slices.Equal(got.Keys, tt.want) {
+				t.Errorf("ParseSort(%q) = %+v, want %+v", tt.spec, got.Keys, tt.want)
+			}
+		})
+	}
+}
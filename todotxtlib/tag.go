@@ -0,0 +1,67 @@
+package todotxtlib
+
+import (
+	"regexp"
+	"strings"
+)
+
+var tagRe = regexp.MustCompile(`^(\w[\w-]*):([^\s:]\S*)$`)
+var tagKeyRe = regexp.MustCompile(`^\w[\w-]*$`)
+
+// Tag is a key:value pair in the text of a todo, e.g. due:2024-01-31
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// String returns the tag as it appears in the todo text
+func (t Tag) String() string {
+	return t.Key + ":" + t.Value
+}
+
+// Tags holds the tags of a todo in the order they appear in its text
+type Tags []Tag
+
+// Get returns the value of the first tag with the given key
+func (t Tags) Get(key string) (string, bool) {
+	for _, tag := range t {
+		if tag.Key == key {
+			return tag.Value, true
+		}
+	}
+	return "", false
+}
+
+// Keys returns the unique tag keys in the order they first appear
+func (t Tags) Keys() []string {
+	keys := []string{}
+	seen := make(map[string]struct{})
+	for _, tag := range t {
+		if _, ok := seen[tag.Key]; !ok {
+			seen[tag.Key] = struct{}{}
+			keys = append(keys, tag.Key)
+		}
+	}
+	return keys
+}
+
+// ParseTag parses a single word as a key:value tag. URLs such as
+// https://example.com are not treated as tags.
+func ParseTag(word string) (Tag, bool) {
+	match := tagRe.FindStringSubmatch(word)
+	if match == nil || strings.HasPrefix(match[2], "//") {
+		return Tag{}, false
+	}
+	return Tag{Key: match[1], Value: match[2]}, true
+}
+
+// parseTags returns all key:value tags in the text
+func parseTags(text string) Tags {
+	tags := Tags{}
+	for _, word := range strings.Fields(text) {
+		if tag, ok := ParseTag(word); ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
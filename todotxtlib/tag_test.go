@@ -0,0 +1,89 @@
+package todotxtlib
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		name   string
+		word   string
+		want   Tag
+		wantOk bool
+	}{
+		{
+			name:   "simple tag",
+			word:   "due:2024-12-31",
+			want:   Tag{Key: "due", Value: "2024-12-31"},
+			wantOk: true,
+		},
+		{
+			name:   "key with dash",
+			word:   "waiting-on:bob",
+			want:   Tag{Key: "waiting-on", Value: "bob"},
+			wantOk: true,
+		},
+		{
+			name:   "value with colon",
+			word:   "at:10:30",
+			want:   Tag{Key: "at", Value: "10:30"},
+			wantOk: true,
+		},
+		{
+			name:   "url",
+			word:   "https://example.com/path",
+			wantOk: false,
+		},
+		{
+			name:   "empty value",
+			word:   "note:",
+			wantOk: false,
+		},
+		{
+			name:   "project",
+			word:   "+project:x",
+			wantOk: false,
+		},
+		{
+			name:   "plain word",
+			word:   "groceries",
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseTag(tt.word)
+			if ok != tt.wantOk {
+				t.Fatalf("ParseTag() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTags_Get(t *testing.T) {
+	tags := parseTags("Call bob due:2024-12-31 see http://example.com rec:1w due:2025-01-01")
+
+	want := Tags{
+		{Key: "due", Value: "2024-12-31"},
+		{Key: "rec", Value: "1w"},
+		{Key: "due", Value: "2025-01-01"},
+	}
+	if !slices.Equal(tags, want) {
+		t.Fatalf("parseTags() = %v, want %v", tags, want)
+	}
+
+	if value, ok := tags.Get("due"); !ok || value != "2024-12-31" {
+		t.Errorf("Get(due) = %v, %v, want 2024-12-31, true", value, ok)
+	}
+	if _, ok := tags.Get("http"); ok {
+		t.Error("Get(http) found a tag for a URL")
+	}
+	if keys := tags.Keys(); !slices.Equal(keys, []string{"due", "rec"}) {
+		t.Errorf("Keys() = %v, want [due rec]", keys)
+	}
+}
@@ -2,6 +2,7 @@ package todotxtlib
 
 import (
 	"bytes"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -155,3 +156,11 @@ func containsTodo(todos []Todo, target Todo) bool {
 	}
 	return false
 }
+
+// failingWriter is a Writer that always fails, for testing error handling
+type failingWriter struct{}
+
+// Write always returns an error
+func (w failingWriter) Write(todos []Todo) error {
+	return errors.New("write failed")
+}
@@ -1,32 +1,42 @@
 package todotxtlib
 
 import (
+	"fmt"
 	"regexp"
 	"slices"
 	"strings"
+	"time"
 )
 
 var projectRe = regexp.MustCompile(`\+(\w+)`)
 var contextRe = regexp.MustCompile(`@\w+`)
 var priorityRe = regexp.MustCompile(`^\(([A-Z])\)`)
 var doneRe = regexp.MustCompile(`^x `)
-var tagRe = regexp.MustCompile(`\w+:\S+`)
+var datesRe = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})(?: (\d{4}-\d{2}-\d{2}))?(?: |$)`)
 
 type Todo struct {
-	Text     string
-	Done     bool
-	Priority string
-	Projects []string
-	Contexts []string
+	Text        string
+	Done        bool
+	Priority    string
+	Projects    []string
+	Contexts    []string
+	Tags        Tags
+	CreatedAt   time.Time // zero if the todo has no creation date
+	CompletedAt time.Time // zero if the todo has no completion date
+	LineNumber  int       // 1-based position in the todo list, not counting blank lines, zero if the todo is not in a list
 }
 
 func NewTodo(text string) Todo {
+	completedAt, createdAt := parseDates(text)
 	todo := Todo{
-		Text:     text,
-		Done:     parseDone(text),
-		Priority: parsePriority(text),
-		Projects: parseProjects(text),
-		Contexts: parseContexts(text),
+		Text:        text,
+		Done:        parseDone(text),
+		Priority:    parsePriority(text),
+		Projects:    parseProjects(text),
+		Contexts:    parseContexts(text),
+		Tags:        parseTags(text),
+		CreatedAt:   createdAt,
+		CompletedAt: completedAt,
 	}
 
 	return todo
@@ -36,16 +46,156 @@ func (t Todo) Prioritised() bool {
 	return t.Priority != ""
 }
 
+// ToggleDone completes the todo with today's date, or reopens it if it is already done.
 func (t *Todo) ToggleDone() {
 	if t.Done {
-		t.Done = false
-		t.Text = strings.TrimPrefix(t.Text, "x ")
+		t.Reopen(false)
 	} else {
-		t.Done = true
-		t.Text = strings.Join([]string{"x ", t.Text}, "")
+		t.Complete(time.Now(), false)
 	}
 }
 
+// Complete marks the todo as done on the given date. The priority is dropped as
+// the todo.txt format requires; if keepPriority is set it is stored in a pri: tag
+// so that Reopen can restore it.
+func (t *Todo) Complete(date time.Time, keepPriority bool) {
+	if t.Done {
+		return
+	}
+
+	priority := t.Priority
+	t.SetPriority("")
+
+	completedAt := date.Format(DateLayout)
+	t.Text = strings.Join([]string{"x ", completedAt, " ", t.Text}, "")
+	t.Done = true
+	t.CompletedAt = parseDate(completedAt)
+	if keepPriority && priority != "" {
+		t.SetTag("pri", priority)
+	}
+}
+
+// Reopen marks the todo as not done, removing the completion date. If
+// restorePriority is set, a priority saved in a pri: tag by Complete is restored;
+// pri: tags with other values, such as pri:high, are always left alone
+func (t *Todo) Reopen(restorePriority bool) {
+	if !t.Done {
+		return
+	}
+
+	completedAt, _ := parseDates(t.Text)
+	t.Text = strings.TrimPrefix(t.Text, "x ")
+	if !completedAt.IsZero() {
+		t.Text = strings.TrimPrefix(t.Text, completedAt.Format(DateLayout)+" ")
+	}
+	t.Done = false
+	t.CompletedAt = time.Time{}
+
+	if priority, ok := t.GetTag("pri"); ok && restorePriority && priorityRe.MatchString("("+priority+")") {
+		t.RemoveTag("pri")
+		if t.Priority == "" {
+			t.SetPriority(priority)
+		}
+	}
+}
+
+// Due returns the due date set by the due: tag
+func (t Todo) Due() (time.Time, bool) {
+	return t.tagDate("due")
+}
+
+// DueIn returns the number of days from today until the todo is due,
+// which is negative if the todo is overdue
+func (t Todo) DueIn(today time.Time) (int, bool) {
+	due, ok := t.Due()
+	if !ok {
+		return 0, false
+	}
+	return daysBetween(today, due), true
+}
+
+// Threshold returns the threshold date set by the t: tag, before which the
+// todo is not yet actionable
+func (t Todo) Threshold() (time.Time, bool) {
+	return t.tagDate("t")
+}
+
+// Actionable reports whether the todo can be worked on today, i.e. it has no
+// threshold date or the threshold date is not in the future
+func (t Todo) Actionable(today time.Time) bool {
+	threshold, ok := t.Threshold()
+	return !ok || daysBetween(today, threshold) <= 0
+}
+
+// tagDate parses the value of a tag as a date
+func (t Todo) tagDate(key string) (time.Time, bool) {
+	value, ok := t.GetTag(key)
+	if !ok {
+		return time.Time{}, false
+	}
+	date := parseDate(value)
+	return date, !date.IsZero()
+}
+
+// SetCreatedAt sets the creation date of the todo, replacing any existing one.
+// The date follows the priority, or the completion date of a done todo; done
+// todos without a completion date are left unchanged, as a single date after
+// the done marker would be read as the completion date.
+func (t *Todo) SetCreatedAt(date time.Time) {
+	prefix := ""
+	if priority := priorityRe.FindString(t.Text); priority != "" {
+		prefix = priority + " "
+	}
+	if t.Done {
+		completedAt, _ := parseDates(t.Text)
+		if completedAt.IsZero() {
+			return
+		}
+		prefix = "x " + completedAt.Format(DateLayout) + " "
+	}
+
+	rest := strings.TrimPrefix(t.Text, prefix)
+	if !t.CreatedAt.IsZero() {
+		rest = strings.TrimPrefix(rest, t.CreatedAt.Format(DateLayout)+" ")
+	}
+
+	createdAt := date.Format(DateLayout)
+	t.Text = strings.Join([]string{prefix, createdAt, " ", rest}, "")
+	t.CreatedAt = parseDate(createdAt)
+}
+
+// Append adds text to the end of the todo
+func (t *Todo) Append(text string) {
+	t.setText(strings.TrimSpace(t.Text + " " + text))
+}
+
+// Prepend adds text to the start of the todo, after its done marker, priority and dates
+func (t *Todo) Prepend(text string) {
+	prefix := linePrefix(t.Text)
+	rest := strings.TrimSpace(t.Text[len(prefix):])
+	if prefix != "" && !strings.HasSuffix(prefix, " ") {
+		prefix += " "
+	}
+	t.setText(strings.TrimSpace(prefix + text + " " + rest))
+}
+
+// setText replaces the text of the todo, parsing it again but keeping its line number
+func (t *Todo) setText(text string) {
+	lineNumber := t.LineNumber
+	*t = NewTodo(text)
+	t.LineNumber = lineNumber
+}
+
+// ParsePriority returns the priority written as a single letter, in upper case,
+// or an error if it is not a letter. An empty priority stands for no priority
+func ParsePriority(priority string) (string, error) {
+	upper := strings.ToUpper(priority)
+	if upper != "" && !priorityRe.MatchString("("+upper+")") {
+		return "", fmt.Errorf("invalid priority %q, expected a letter from A to Z", priority)
+	}
+	return upper, nil
+}
+
 // SetPriority sets the priority of the todo item.
 func (t *Todo) SetPriority(priority string) {
 	// Remove existing priority from the text
@@ -84,6 +234,14 @@ func (t Todo) Equals(other Todo) bool {
 		return false
 	}
 
+	if !slices.Equal(t.Tags, other.Tags) {
+		return false
+	}
+
+	if !t.CreatedAt.Equal(other.CreatedAt) || !t.CompletedAt.Equal(other.CompletedAt) {
+		return false
+	}
+
 	return true
 }
 
@@ -118,6 +276,27 @@ func parseDone(text string) bool {
 	return doneRe.MatchString(text)
 }
 
+// parseDates extracts the completion and creation dates that follow the done
+// marker and priority at the start of the text. A done todo lists its
+// completion date first, followed by the optional creation date.
+func parseDates(text string) (completedAt, createdAt time.Time) {
+	done := parseDone(text)
+	rest := strings.TrimPrefix(text, "x ")
+	if priority := priorityRe.FindString(rest); priority != "" {
+		rest = strings.TrimPrefix(rest[len(priority):], " ")
+	}
+
+	match := datesRe.FindStringSubmatch(rest)
+	if match == nil {
+		return time.Time{}, time.Time{}
+	}
+
+	if done {
+		return parseDate(match[1]), parseDate(match[2])
+	}
+	return time.Time{}, parseDate(match[1])
+}
+
 // SetContexts sets the contexts of a todo
 func (t *Todo) SetContexts(contexts []string) {
 	// Remove all existing contexts from text
@@ -200,14 +379,75 @@ func (t *Todo) RemoveProject(project string) {
 	}
 }
 
+// GetTag returns the value of the first tag with the given key
+func (t Todo) GetTag(key string) (string, bool) {
+	return t.Tags.Get(key)
+}
+
+// SetTag sets the value of a tag, replacing the first tag with the same key in
+// place, or appending the tag to the end of the text if there is none
+func (t *Todo) SetTag(key, value string) {
+	tag := Tag{Key: key, Value: value}
+	words := strings.Split(t.Text, " ")
+	replaced := false
+	for i, word := range words {
+		if existing, ok := ParseTag(word); ok && existing.Key == key {
+			words[i] = tag.String()
+			replaced = true
+			break
+		}
+	}
+
+	if replaced {
+		t.Text = strings.Join(words, " ")
+	} else {
+		t.Text = strings.TrimSpace(t.Text + " " + tag.String())
+	}
+	t.Tags = parseTags(t.Text)
+}
+
+// RemoveTag removes all tags with the given key from the text
+func (t *Todo) RemoveTag(key string) {
+	words := strings.Split(t.Text, " ")
+	kept := words[:0]
+	for _, word := range words {
+		if tag, ok := ParseTag(word); ok && tag.Key == key {
+			continue
+		}
+		kept = append(kept, word)
+	}
+
+	t.Text = strings.TrimSpace(strings.Join(kept, " "))
+	t.Tags = parseTags(t.Text)
+}
+
 // addToText adds a project or context to the end of the todo text
 func (t *Todo) addToText(item string) {
-	if !strings.Contains(t.Text, item) {
+	if !itemRe(item).MatchString(t.Text) {
 		t.Text = strings.TrimSpace(t.Text + " " + item)
 	}
 }
 
 // removeFromText removes a project or context from the todo text
 func (t *Todo) removeFromText(item string) {
-	t.Text = strings.ReplaceAll(t.Text, item, "")
+	t.Text = strings.Join(strings.Fields(itemRe(item).ReplaceAllString(t.Text, "$1")), " ")
+}
+
+// itemRe matches a project or context as a whole word, so that e.g. +web does not match +website
+func itemRe(item string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|\s)` + regexp.QuoteMeta(item) + `\b`)
+}
+
+// linePrefix returns the done marker, priority and dates at the start of a todo line
+func linePrefix(line string) string {
+	rest := strings.TrimPrefix(line, "x ")
+	if priority := priorityRe.FindString(rest); priority != "" {
+		rest = strings.TrimPrefix(rest[len(priority):], " ")
+	}
+	for range 2 {
+		if match := datesRe.FindString(rest); match != "" {
+			rest = rest[len(match):]
+		}
+	}
+	return line[:len(line)-len(rest)]
 }
@@ -2,6 +2,7 @@ package todotxtlib
 
 import (
 	"testing"
+	"time"
 )
 
 func TestNewTodo(t *testing.T) {
@@ -77,6 +78,68 @@ func TestNewTodo(t *testing.T) {
 				Contexts: []string{"@store"},
 			},
 		},
+		{
+			name: "with creation date",
+			text: "2024-01-01 Buy groceries",
+			want: Todo{
+				Text:      "2024-01-01 Buy groceries",
+				Projects:  []string{},
+				Contexts:  []string{},
+				CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name: "with priority and creation date",
+			text: "(A) 2024-01-01 Buy groceries",
+			want: Todo{
+				Text:      "(A) 2024-01-01 Buy groceries",
+				Priority:  "A",
+				Projects:  []string{},
+				Contexts:  []string{},
+				CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name: "done with completion and creation dates",
+			text: "x 2024-01-02 2023-12-30 Buy groceries",
+			want: Todo{
+				Text:        "x 2024-01-02 2023-12-30 Buy groceries",
+				Done:        true,
+				Projects:    []string{},
+				Contexts:    []string{},
+				CreatedAt:   time.Date(2023, 12, 30, 0, 0, 0, 0, time.Local),
+				CompletedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name: "done with completion date only",
+			text: "x 2024-01-02 Buy groceries",
+			want: Todo{
+				Text:        "x 2024-01-02 Buy groceries",
+				Done:        true,
+				Projects:    []string{},
+				Contexts:    []string{},
+				CompletedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name: "date not at start of text",
+			text: "Buy groceries 2024-01-01",
+			want: Todo{
+				Text:     "Buy groceries 2024-01-01",
+				Projects: []string{},
+				Contexts: []string{},
+			},
+		},
+		{
+			name: "invalid date",
+			text: "2024-13-45 Buy groceries",
+			want: Todo{
+				Text:     "2024-13-45 Buy groceries",
+				Projects: []string{},
+				Contexts: []string{},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -91,6 +154,12 @@ func TestNewTodo(t *testing.T) {
 			if got.Priority != tt.want.Priority {
 				t.Errorf("NewTodo() Priority = %v, want %v", got.Priority, tt.want.Priority)
 			}
+			if !got.CreatedAt.Equal(tt.want.CreatedAt) {
+				t.Errorf("NewTodo() CreatedAt = %v, want %v", got.CreatedAt, tt.want.CreatedAt)
+			}
+			if !got.CompletedAt.Equal(tt.want.CompletedAt) {
+				t.Errorf("NewTodo() CompletedAt = %v, want %v", got.CompletedAt, tt.want.CompletedAt)
+			}
 			if len(got.Projects) != len(tt.want.Projects) {
 				t.Errorf("NewTodo() Projects length = %v, want %v", len(got.Projects), len(tt.want.Projects))
 			} else {
@@ -146,6 +215,8 @@ func TestTodo_Prioritised(t *testing.T) {
 }
 
 func TestTodo_ToggleDone(t *testing.T) {
+	today := time.Now().Format(DateLayout)
+
 	tests := []struct {
 		name string
 		todo Todo
@@ -154,7 +225,7 @@ func TestTodo_ToggleDone(t *testing.T) {
 		{
 			name: "not done to done",
 			todo: Todo{Text: "Buy groceries"},
-			want: "x Buy groceries",
+			want: "x " + today + " Buy groceries",
 		},
 		{
 			name: "done to not done",
@@ -164,7 +235,12 @@ func TestTodo_ToggleDone(t *testing.T) {
 		{
 			name: "with priority",
 			todo: Todo{Text: "(A) Buy groceries", Priority: "A"},
-			want: "x (A) Buy groceries",
+			want: "x " + today + " Buy groceries",
+		},
+		{
+			name: "completed task with completion date",
+			todo: Todo{Text: "x 2024-01-02 2024-01-01 Buy groceries", Done: true},
+			want: "2024-01-01 Buy groceries",
 		},
 		{
 			name: "completed task",
@@ -183,6 +259,181 @@ func TestTodo_ToggleDone(t *testing.T) {
 	}
 }
 
+func TestTodo_Complete(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name         string
+		text         string
+		keepPriority bool
+		want         string
+	}{
+		{
+			name: "without priority",
+			text: "Buy groceries",
+			want: "x 2024-01-15 Buy groceries",
+		},
+		{
+			name: "with creation date",
+			text: "2024-01-01 Buy groceries",
+			want: "x 2024-01-15 2024-01-01 Buy groceries",
+		},
+		{
+			name: "drops priority",
+			text: "(A) 2024-01-01 Buy groceries",
+			want: "x 2024-01-15 2024-01-01 Buy groceries",
+		},
+		{
+			name:         "keeps priority in tag",
+			text:         "(A) Buy groceries +shopping",
+			keepPriority: true,
+			want:         "x 2024-01-15 Buy groceries +shopping pri:A",
+		},
+		{
+			name: "already done",
+			text: "x 2024-01-02 Buy groceries",
+			want: "x 2024-01-02 Buy groceries",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := NewTodo(tt.text)
+			todo.Complete(date, tt.keepPriority)
+			if todo.Text != tt.want {
+				t.Errorf("Complete() Text = %v, want %v", todo.Text, tt.want)
+			}
+			if !todo.Done {
+				t.Error("Complete() Done = false, want true")
+			}
+			if todo.Priority != "" {
+				t.Errorf("Complete() Priority = %v, want none", todo.Priority)
+			}
+			if got := NewTodo(todo.Text); !got.CompletedAt.Equal(todo.CompletedAt) {
+				t.Errorf("Complete() CompletedAt = %v, want %v", todo.CompletedAt, got.CompletedAt)
+			}
+		})
+	}
+}
+
+func TestTodo_Reopen(t *testing.T) {
+	tests := []struct {
+		name            string
+		text            string
+		restorePriority bool
+		want            string
+		wantPriority    string
+	}{
+		{
+			name: "with completion date",
+			text: "x 2024-01-15 Buy groceries",
+			want: "Buy groceries",
+		},
+		{
+			name: "with completion and creation dates",
+			text: "x 2024-01-15 2024-01-01 Buy groceries",
+			want: "2024-01-01 Buy groceries",
+		},
+		{
+			name:            "restores priority from tag",
+			text:            "x 2024-01-15 2024-01-01 Buy groceries pri:A +shopping",
+			restorePriority: true,
+			want:            "(A) 2024-01-01 Buy groceries +shopping",
+			wantPriority:    "A",
+		},
+		{
+			name: "keeps priority tag unless restoring",
+			text: "x 2024-01-15 Buy groceries pri:A",
+			want: "Buy groceries pri:A",
+		},
+		{
+			name:            "keeps pri tag that is not a priority",
+			text:            "x 2024-01-01 ship it pri:high +work",
+			restorePriority: true,
+			want:            "ship it pri:high +work",
+		},
+		{
+			name:         "keeps inline priority",
+			text:         "x (B) Buy groceries",
+			want:         "(B) Buy groceries",
+			wantPriority: "B",
+		},
+		{
+			name: "not done",
+			text: "Buy groceries",
+			want: "Buy groceries",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := NewTodo(tt.text)
+			todo.Reopen(tt.restorePriority)
+			if todo.Text != tt.want {
+				t.Errorf("Reopen() Text = %v, want %v", todo.Text, tt.want)
+			}
+			if todo.Done {
+				t.Error("Reopen() Done = true, want false")
+			}
+			if todo.Priority != tt.wantPriority {
+				t.Errorf("Reopen() Priority = %v, want %v", todo.Priority, tt.wantPriority)
+			}
+			if !todo.CompletedAt.IsZero() {
+				t.Errorf("Reopen() CompletedAt = %v, want zero", todo.CompletedAt)
+			}
+		})
+	}
+}
+
+func TestTodo_SetCreatedAt(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "without priority",
+			text: "Buy groceries",
+			want: "2024-01-15 Buy groceries",
+		},
+		{
+			name: "after priority",
+			text: "(A) Buy groceries",
+			want: "(A) 2024-01-15 Buy groceries",
+		},
+		{
+			name: "replaces existing date",
+			text: "(A) 2023-12-01 Buy groceries",
+			want: "(A) 2024-01-15 Buy groceries",
+		},
+		{
+			name: "after completion date",
+			text: "x 2024-01-20 Buy groceries",
+			want: "x 2024-01-20 2024-01-15 Buy groceries",
+		},
+		{
+			name: "done without completion date",
+			text: "x Buy groceries",
+			want: "x Buy groceries",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := NewTodo(tt.text)
+			todo.SetCreatedAt(date)
+			if todo.Text != tt.want {
+				t.Errorf("SetCreatedAt() Text = %v, want %v", todo.Text, tt.want)
+			}
+			if got := NewTodo(todo.Text); !got.CreatedAt.Equal(todo.CreatedAt) {
+				t.Errorf("SetCreatedAt() CreatedAt = %v, want %v", todo.CreatedAt, got.CreatedAt)
+			}
+		})
+	}
+}
+
 func TestTodo_SetPriority(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -284,6 +535,12 @@ func TestTodo_Equals(t *testing.T) {
 			todo2: Todo{Text: "Buy groceries @work", Contexts: []string{"@work"}},
 			want:  false,
 		},
+		{
+			name:  "different creation date",
+			todo1: Todo{Text: "Buy groceries", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)},
+			todo2: Todo{Text: "Buy groceries", CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)},
+			want:  false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -295,6 +552,192 @@ func TestTodo_Equals(t *testing.T) {
 	}
 }
 
+func TestTodo_SetTag(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		key   string
+		value string
+		want  string
+	}{
+		{
+			name:  "append new tag",
+			text:  "Buy groceries +shopping",
+			key:   "due",
+			value: "2024-12-31",
+			want:  "Buy groceries +shopping due:2024-12-31",
+		},
+		{
+			name:  "replace tag in place",
+			text:  "Buy groceries due:2024-12-01 +shopping",
+			key:   "due",
+			value: "2024-12-31",
+			want:  "Buy groceries due:2024-12-31 +shopping",
+		},
+		{
+			name:  "ignore url",
+			text:  "Read http://example.com",
+			key:   "http",
+			value: "x",
+			want:  "Read http://example.com http:x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := NewTodo(tt.text)
+			todo.SetTag(tt.key, tt.value)
+			if todo.Text != tt.want {
+				t.Errorf("SetTag() Text = %v, want %v", todo.Text, tt.want)
+			}
+			if value, ok := todo.GetTag(tt.key); !ok || value != tt.value {
+				t.Errorf("GetTag() = %v, %v, want %v, true", value, ok, tt.value)
+			}
+		})
+	}
+}
+
+func TestTodo_RemoveTag(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		key  string
+		want string
+	}{
+		{
+			name: "remove tag in the middle",
+			text: "Buy groceries due:2024-12-31 +shopping",
+			key:  "due",
+			want: "Buy groceries +shopping",
+		},
+		{
+			name: "remove repeated tags",
+			text: "Buy groceries due:2024-12-31 due:2025-01-01",
+			key:  "due",
+			want: "Buy groceries",
+		},
+		{
+			name: "remove missing tag",
+			text: "Buy groceries",
+			key:  "due",
+			want: "Buy groceries",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := NewTodo(tt.text)
+			todo.RemoveTag(tt.key)
+			if todo.Text != tt.want {
+				t.Errorf("RemoveTag() Text = %v, want %v", todo.Text, tt.want)
+			}
+			if _, ok := todo.GetTag(tt.key); ok {
+				t.Errorf("GetTag() found %v after RemoveTag()", tt.key)
+			}
+		})
+	}
+}
+
+func TestTodo_Due(t *testing.T) {
+	today := time.Date(2024, 3, 10, 23, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		text     string
+		wantOk   bool
+		wantDays int
+	}{
+		{
+			name:     "overdue",
+			text:     "Pay bills due:2024-03-01",
+			wantOk:   true,
+			wantDays: -9,
+		},
+		{
+			name:     "due today",
+			text:     "Pay bills due:2024-03-10",
+			wantOk:   true,
+			wantDays: 0,
+		},
+		{
+			name:     "due next month",
+			text:     "Pay bills due:2024-04-10",
+			wantOk:   true,
+			wantDays: 31,
+		},
+		{
+			name:   "no due date",
+			text:   "Pay bills",
+			wantOk: false,
+		},
+		{
+			name:   "invalid due date",
+			text:   "Pay bills due:tomorrow",
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := NewTodo(tt.text)
+			days, ok := todo.DueIn(today)
+			if ok != tt.wantOk {
+				t.Fatalf("DueIn() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if days != tt.wantDays {
+				t.Errorf("DueIn() = %v, want %v", days, tt.wantDays)
+			}
+			if _, ok := todo.Due(); ok != tt.wantOk {
+				t.Errorf("Due() ok = %v, want %v", ok, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestTodo_Actionable(t *testing.T) {
+	today := time.Date(2024, 3, 10, 8, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{
+			name: "no threshold",
+			text: "Plan holiday",
+			want: true,
+		},
+		{
+			name: "threshold in the past",
+			text: "Plan holiday t:2024-03-01",
+			want: true,
+		},
+		{
+			name: "threshold today",
+			text: "Plan holiday t:2024-03-10",
+			want: true,
+		},
+		{
+			name: "threshold in the future",
+			text: "Plan holiday t:2024-03-11",
+			want: false,
+		},
+		{
+			name: "invalid threshold",
+			text: "Plan holiday t:later",
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewTodo(tt.text).Actionable(today); got != tt.want {
+				t.Errorf("Actionable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestTodo_SetContexts(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -552,3 +995,19 @@ func TestTodo_RemoveProject(t *testing.T) {
 		})
 	}
 }
+
+func TestTodo_RemoveProject_Text(t *testing.T) {
+	todo := NewTodo("(A) fix +web layout for +website @work")
+	todo.RemoveProject("+web")
+
+	want := "(A) fix layout for +website @work"
+	if todo.Text != want {
+		t.Errorf("RemoveProject() Text = %q, want %q", todo.Text, want)
+	}
+
+	todo.AddProject("+web")
+	want = "(A) fix layout for +website @work +web"
+	if todo.Text != want {
+		t.Errorf("AddProject() Text = %q, want %q", todo.Text, want)
+	}
+}
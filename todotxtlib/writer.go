@@ -1,8 +1,11 @@
 package todotxtlib
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 )
 
 // Writer handles writing Todo structs to an output destination
@@ -17,25 +20,176 @@ func NewFileWriter(path string) Writer {
 	}
 }
 
+// maxSymlinks is the number of symlinks followed before giving up on a loop
+const maxSymlinks = 40
+
 // fileWriter is a Writer that writes Todo structs to a file
 type fileWriter struct {
 	path string
 }
 
-// Write writes the given todos to the file
+// Write writes the given todos to the file. The todos are written to a temporary
+// file in the same directory, which is synced and renamed over the original, so
+// a crash or full disk never leaves a partially written file. The previous
+// contents are kept in a .bak file next to it. If the path is a symlink, the file
+// it points to is replaced and the link is left in place.
 func (w *fileWriter) Write(todos []Todo) error {
-	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	path, err := resolveSymlinks(w.path)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	for _, todo := range todos {
-		if _, err := file.WriteString(todo.Text + "\n"); err != nil {
+	mode := os.FileMode(0644)
+	previous, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		info, err := os.Stat(path)
+		if err != nil {
 			return err
 		}
+		mode = info.Mode().Perm()
+		if err := writeFileAtomic(path+".bak", previous, mode); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	return writeFileAtomic(path, formatTodos(todos), mode)
+}
+
+// formatTodos returns the todo.txt content for the given todos
+func formatTodos(todos []Todo) []byte {
+	var buffer bytes.Buffer
+	for _, todo := range todos {
+		buffer.WriteString(todo.Text + "\n")
+	}
+	return buffer.Bytes()
+}
+
+// writeFileAtomic replaces the file at path with data by writing a synced
+// temporary file in the same directory and renaming it over the original
+func writeFileAtomic(path string, data []byte, mode os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	if _, err = file.Write(data); err != nil {
+		return err
 	}
+	if err = file.Chmod(mode); err != nil {
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(file.Name(), path); err != nil {
+		return err
+	}
+
+	// Sync the directory so the rename itself survives a crash; not all
+	// platforms support this, so failures are ignored
+	if dirFile, err := os.Open(dir); err == nil {
+		dirFile.Sync()
+		dirFile.Close()
+	}
+	return nil
+}
 
+// resolveSymlinks follows the chain of symlinks at path and returns the path of
+// the file it points to, which may not exist yet
+func resolveSymlinks(path string) (string, error) {
+	for range maxSymlinks {
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			return path, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			return path, nil
+		}
+
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = target
+	}
+	return "", fmt.Errorf("too many levels of symbolic links: %s", path)
+}
+
+// revertibleWriter is a Writer that can take back its last successful write
+type revertibleWriter interface {
+	Writer
+	Revert() error
+}
+
+// NewAppendFileWriter returns a new Writer that appends to the specified file,
+// creating it if needed
+func NewAppendFileWriter(path string) Writer {
+	return &appendFileWriter{
+		path:     path,
+		lastSize: -1,
+	}
+}
+
+// appendFileWriter is a Writer that appends Todo structs to the end of a file
+type appendFileWriter struct {
+	path     string
+	lastSize int64 // size of the file before the last successful write, -1 if there is none
+}
+
+// Write appends the given todos to the file. If the write fails partway, the
+// file is truncated back to its original length so no partial lines remain.
+func (w *appendFileWriter) Write(todos []Todo) error {
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(formatTodos(todos)); err != nil {
+		file.Truncate(info.Size())
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	w.lastSize = info.Size()
+	return nil
+}
+
+// Revert truncates the file back to its length before the last successful write
+func (w *appendFileWriter) Revert() error {
+	if w.lastSize < 0 {
+		return nil
+	}
+	if err := os.Truncate(w.path, w.lastSize); err != nil {
+		return err
+	}
+	w.lastSize = -1
 	return nil
 }
 
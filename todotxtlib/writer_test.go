@@ -83,6 +83,194 @@ func TestFileWriter_Write(t *testing.T) {
 	})
 }
 
+func TestFileWriter_WriteAtomically(t *testing.T) {
+	t.Run("keeps the previous contents in a backup", func(t *testing.T) {
+		tempDir := t.TempDir()
+		tempFile := filepath.Join(tempDir, "todo.txt")
+		writer := NewFileWriter(tempFile)
+
+		if err := writer.Write([]Todo{{Text: "first"}}); err != nil {
+			t.Fatalf("FileWriter.Write() error = %v", err)
+		}
+		if _, err := os.Stat(tempFile + ".bak"); !os.IsNotExist(err) {
+			t.Errorf("Expected no backup for a new file, got error %v", err)
+		}
+
+		if err := writer.Write([]Todo{{Text: "second"}}); err != nil {
+			t.Fatalf("FileWriter.Write() error = %v", err)
+		}
+		if err := writer.Write([]Todo{{Text: "third"}}); err != nil {
+			t.Fatalf("FileWriter.Write() error = %v", err)
+		}
+
+		backup, err := os.ReadFile(tempFile + ".bak")
+		if err != nil {
+			t.Fatalf("Failed to read backup file: %v", err)
+		}
+		if string(backup) != "second\n" {
+			t.Errorf("Backup content = %q, want %q", string(backup), "second\n")
+		}
+
+		entries, err := os.ReadDir(tempDir)
+		if err != nil {
+			t.Fatalf("Failed to read directory: %v", err)
+		}
+		if len(entries) != 2 {
+			t.Errorf("Expected only todo.txt and todo.txt.bak, got %d entries", len(entries))
+		}
+	})
+
+	t.Run("preserves the file mode", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "todo.txt")
+		if err := os.WriteFile(tempFile, []byte("old\n"), 0600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		if err := NewFileWriter(tempFile).Write([]Todo{{Text: "new"}}); err != nil {
+			t.Fatalf("FileWriter.Write() error = %v", err)
+		}
+
+		info, err := os.Stat(tempFile)
+		if err != nil {
+			t.Fatalf("Failed to stat test file: %v", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("File mode = %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+		}
+	})
+
+	t.Run("writes through symlinks", func(t *testing.T) {
+		tempDir := t.TempDir()
+		target := filepath.Join(tempDir, "real.txt")
+		link := filepath.Join(tempDir, "todo.txt")
+		if err := os.WriteFile(target, []byte("old\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		if err := os.Symlink("real.txt", link); err != nil {
+			t.Skipf("Symlinks not supported: %v", err)
+		}
+
+		if err := NewFileWriter(link).Write([]Todo{{Text: "new"}}); err != nil {
+			t.Fatalf("FileWriter.Write() error = %v", err)
+		}
+
+		info, err := os.Lstat(link)
+		if err != nil {
+			t.Fatalf("Failed to stat link: %v", err)
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			t.Error("Expected todo.txt to still be a symlink")
+		}
+
+		content, err := os.ReadFile(target)
+		if err != nil {
+			t.Fatalf("Failed to read target file: %v", err)
+		}
+		if string(content) != "new\n" {
+			t.Errorf("Target content = %q, want %q", string(content), "new\n")
+		}
+		if _, err := os.Stat(target + ".bak"); err != nil {
+			t.Errorf("Expected backup next to the target file: %v", err)
+		}
+	})
+}
+
+func TestFileWriter_RoundTripDates(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "test.todo.txt")
+	content := "(A) 2024-01-01 Buy groceries\nx 2024-01-02 2023-12-30 Call mom\n"
+	if err := os.WriteFile(tempFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	todos, err := NewFileReader(tempFile).Read()
+	if err != nil {
+		t.Fatalf("FileReader.Read() error = %v", err)
+	}
+	if todos[1].CompletedAt.Format(DateLayout) != "2024-01-02" {
+		t.Errorf("CompletedAt = %v, want 2024-01-02", todos[1].CompletedAt)
+	}
+
+	if err := NewFileWriter(tempFile).Write(todos); err != nil {
+		t.Fatalf("FileWriter.Write() error = %v", err)
+	}
+
+	got, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	if string(got) != content {
+		t.Errorf("FileWriter.Write() content = %q, want %q", string(got), content)
+	}
+}
+
+func TestAppendFileWriter_Write(t *testing.T) {
+	tempDir := t.TempDir()
+
+	t.Run("appends to existing file", func(t *testing.T) {
+		tempFile := filepath.Join(tempDir, "done.txt")
+		if err := os.WriteFile(tempFile, []byte("x first\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		writer := NewAppendFileWriter(tempFile)
+		if err := writer.Write([]Todo{{Text: "x second"}, {Text: "x third"}}); err != nil {
+			t.Fatalf("AppendFileWriter.Write() error = %v", err)
+		}
+
+		content, err := os.ReadFile(tempFile)
+		if err != nil {
+			t.Fatalf("Failed to read test file: %v", err)
+		}
+		if want := "x first\nx second\nx third\n"; string(content) != want {
+			t.Errorf("AppendFileWriter.Write() content = %q, want %q", string(content), want)
+		}
+	})
+
+	t.Run("creates missing file", func(t *testing.T) {
+		tempFile := filepath.Join(tempDir, "new-done.txt")
+
+		writer := NewAppendFileWriter(tempFile)
+		if err := writer.Write([]Todo{{Text: "x first"}}); err != nil {
+			t.Fatalf("AppendFileWriter.Write() error = %v", err)
+		}
+
+		content, err := os.ReadFile(tempFile)
+		if err != nil {
+			t.Fatalf("Failed to read test file: %v", err)
+		}
+		if want := "x first\n"; string(content) != want {
+			t.Errorf("AppendFileWriter.Write() content = %q, want %q", string(content), want)
+		}
+	})
+}
+
+func TestAppendFileWriter_Revert(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "done.txt")
+	if err := os.WriteFile(tempFile, []byte("x first\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	writer := NewAppendFileWriter(tempFile).(*appendFileWriter)
+	if err := writer.Write([]Todo{{Text: "x second"}}); err != nil {
+		t.Fatalf("AppendFileWriter.Write() error = %v", err)
+	}
+	if err := writer.Revert(); err != nil {
+		t.Fatalf("AppendFileWriter.Revert() error = %v", err)
+	}
+	// Reverting again does nothing, as there is no write left to revert
+	if err := writer.Revert(); err != nil {
+		t.Fatalf("AppendFileWriter.Revert() error = %v", err)
+	}
+
+	content, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	if want := "x first\n"; string(content) != want {
+		t.Errorf("AppendFileWriter.Revert() content = %q, want %q", string(content), want)
+	}
+}
+
 func TestBufferWriter_Write(t *testing.T) {
 	tests := []struct {
 		name    string